@@ -0,0 +1,87 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// The affiliation of users without company in their profile, without known profile, or of bots
+const (
+	affiliationNone    = "(none)"
+	affiliationUnknown = "(unknown)"
+	affiliationBot     = "(bot)"
+)
+
+// Loads the company mapping file, used to normalize the companies found in the GitHub profiles.
+// Each line has the form "<company as found on GitHub> = <affiliation>". Comments start with "#".
+// The key is the lower case company.
+func loadCompanyMapping(fileName string) (map[string]string, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read company mapping file %s: %v", fileName, err)
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Error loading \"%s\": %v", fileName, err)
+	}
+
+	mapping := make(map[string]string)
+	for _, line := range removeComments(lines) {
+		company, affiliation, found := strings.Cut(line, "=")
+		company = strings.TrimSpace(company)
+		affiliation = strings.TrimSpace(affiliation)
+		if !found || company == "" || affiliation == "" {
+			return nil, fmt.Errorf("Invalid company mapping \"%s\" in \"%s\" (expecting \"<company> = <affiliation>\")", line, fileName)
+		}
+		mapping[strings.ToLower(company)] = affiliation
+	}
+	return mapping, nil
+}
+
+// Returns the affiliation of a user, based on the company of their (cached) profile
+func computeAffiliation(login string, profiles map[string]userProfile, companyMapping map[string]string) string {
+	profile, exists := profiles[strings.ToLower(login)]
+	if !exists {
+		return affiliationUnknown
+	}
+	if profile.isBot() {
+		return affiliationBot
+	}
+	company := strings.TrimSpace(profile.company)
+	if company == "" {
+		return affiliationNone
+	}
+	if affiliation, isMapped := companyMapping[strings.ToLower(company)]; isMapped {
+		return affiliation
+	}
+	return company
+}
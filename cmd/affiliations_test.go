@@ -0,0 +1,67 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_loadCompanyMapping(t *testing.T) {
+	mapping, err := loadCompanyMapping("../test-data/company-mapping.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"@cloudbees": "CloudBees", "cloudbees, inc.": "CloudBees"}, mapping)
+
+	_, err = loadCompanyMapping("../test-data/invalid-company-mapping.txt")
+	assert.ErrorContains(t, err, "Invalid company mapping")
+
+	_, err = loadCompanyMapping("../test-data/inexistent.txt")
+	assert.Error(t, err)
+}
+
+func Test_computeAffiliation(t *testing.T) {
+	profiles := map[string]userProfile{
+		"alice": {login: "alice", company: "@CloudBees"},
+		"bob":   {login: "Bob", company: " Red Hat "},
+		"carol": {login: "carol", company: ""},
+		"ci":    {login: "ci", company: "CloudBees", accountType: accountBot},
+	}
+	mapping := map[string]string{"@cloudbees": "CloudBees"}
+
+	tests := []struct {
+		name  string
+		login string
+		want  string
+	}{
+		{"mapped company", "alice", "CloudBees"},
+		{"unmapped company", "BOB", "Red Hat"},
+		{"no company", "carol", affiliationNone},
+		{"no profile", "dave", affiliationUnknown},
+		{"bot", "ci", affiliationBot},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, computeAffiliation(tt.login, profiles, mapping))
+		})
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var aggregateIsExcludeOwnPRs bool
+var aggregateSubmissionsFileNames []string
+var aggregateCommentersFormat string
+
+// aggregateCommentersCmd represents the "aggregate commenters" command
+var aggregateCommentersCmd = &cobra.Command{
+	Use:   "commenters <commenters CSV>",
+	Short: "Computes the number of comments per commenter and month",
+	Long: `Computes, for each commenter and month, the number of comments and the number
+of distinct PRs commented, from a commenters file generated by "get commenters".
+
+With "--exclude_own", the comments made by a user on their own PRs are not counted. This
+requires the submissions files ("--submissions") of the commented PRs to know their author.
+Comments on PRs that are not found in the submissions files are counted.
+
+If not specified, the output file name is derived from the input file name:
+"data/commenters-2024-04.csv" gives "data/comments_per_commenter-2024-04.csv".
+Use "-" as input file name to read from the standard input.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if err := cobra.MinimumNArgs(1)(cmd, args); err != nil {
+			return err
+		}
+		if !fileExist(args[0]) && !isStdStream(args[0]) {
+			return fmt.Errorf("ERROR: %s is not an existing file.\n", args[0])
+		}
+		if err := validateOutputFormat(aggregateCommentersFormat, []string{formatCSV, formatJSON}); err != nil {
+			return err
+		}
+		if aggregateIsExcludeOwnPRs && len(aggregateSubmissionsFileNames) == 0 {
+			return fmt.Errorf("ERROR: \"--exclude_own\" requires the submissions files (\"--submissions\").\n")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return performAggregateCommenters(args[0], aggregateOutputFileName, aggregateCommentersFormat, aggregateIsExcludeOwnPRs, aggregateSubmissionsFileNames)
+	},
+}
+
+func init() {
+	aggregateCmd.AddCommand(aggregateCommentersCmd)
+
+	aggregateCommentersCmd.Flags().StringVarP(&aggregateCommentersFormat, "format", "f", formatCSV, "Output format (csv or json).")
+	aggregateCommentersCmd.Flags().BoolVarP(&aggregateIsExcludeOwnPRs, "exclude_own", "", false, "Don't count the comments made on one's own PRs.")
+	aggregateCommentersCmd.Flags().StringSliceVarP(&aggregateSubmissionsFileNames, "submissions", "s", nil, "Submissions file(s) used to identify the PR authors (can be repeated).")
+}
+
+// Number of comments and of distinct commented PRs of a commenter for a month
+type commenterCount struct {
+	commenter string
+	month     string
+	comments  int
+	prs       int
+}
+
+// Main function of the "aggregate commenters" command
+func performAggregateCommenters(inputFileName string, outputFileName string, format string, isExcludeOwnPRs bool, submissionsFileNames []string) error {
+	comments, err := loadComments(inputFileName)
+	if err != nil {
+		return err
+	}
+
+	var prAuthors map[string]string
+	if isExcludeOwnPRs {
+		prAuthors, err = loadPRauthors(submissionsFileNames)
+		if err != nil {
+			return err
+		}
+	}
+
+	schema := currentSchema(schemaCommentsCount)
+	if outputFileName == "" {
+		outputFileName, err = computeAggregateOutputFileName(inputFileName, schema.kind)
+		if err != nil {
+			return err
+		}
+		outputFileName = strings.TrimSuffix(outputFileName, ".csv") + outputFormatExtension(format)
+	}
+
+	counts := countCommentsPerCommenter(comments, prAuthors)
+
+	var rows [][]string
+	for _, count := range counts {
+		rows = append(rows, []string{count.commenter, count.month, strconv.Itoa(count.comments), strconv.Itoa(count.prs)})
+	}
+
+	if err := writeTableOutput(outputFileName, format, schema.columns, []string{"comments", "PRs"}, rows); err != nil {
+		return err
+	}
+
+	if isVerbose {
+		fmt.Fprintf(os.Stderr, "Aggregated %d comments in %d record(s) (\"%s\")\n", len(comments), len(counts), outputFileName)
+	}
+	return nil
+}
+
+// Counts the comments and distinct commented PRs per commenter and month.
+// If PR authors are supplied, the comments made by the author of the PR are skipped.
+// The result is sorted by month, then by decreasing number of comments and by commenter.
+func countCommentsPerCommenter(comments []commentRecord, prAuthors map[string]string) []commenterCount {
+	countIndex := make(map[string]int)
+	seenPRs := make(map[string]bool)
+	var counts []commenterCount
+
+	for _, comment := range comments {
+		if prAuthors != nil && strings.EqualFold(prAuthors[comment.prRef], comment.commenter) {
+			continue
+		}
+
+		key := comment.commenter + " " + comment.month
+		i, exists := countIndex[key]
+		if !exists {
+			i = len(counts)
+			countIndex[key] = i
+			counts = append(counts, commenterCount{commenter: comment.commenter, month: comment.month})
+		}
+		counts[i].comments++
+
+		prKey := key + " " + comment.prRef
+		if !seenPRs[prKey] {
+			seenPRs[prKey] = true
+			counts[i].prs++
+		}
+	}
+
+	sort.SliceStable(counts, func(i, j int) bool {
+		if counts[i].month != counts[j].month {
+			return counts[i].month < counts[j].month
+		}
+		if counts[i].comments != counts[j].comments {
+			return counts[i].comments > counts[j].comments
+		}
+		return strings.ToLower(counts[i].commenter) < strings.ToLower(counts[j].commenter)
+	})
+	return counts
+}
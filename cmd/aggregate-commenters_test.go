@@ -0,0 +1,100 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_countCommentsPerCommenter(t *testing.T) {
+	comments := []commentRecord{
+		{"jenkinsci/jenkins/1", "bob", "2024-01"},
+		{"jenkinsci/jenkins/1", "bob", "2024-01"},
+		{"jenkinsci/jenkins/2", "bob", "2024-01"},
+		{"jenkinsci/jenkins/2", "alice", "2024-01"},
+		{"jenkinsci/jenkins/1", "Alice", "2024-02"},
+		{"jenkinsci/jenkins/3", "bob", "2024-02"},
+	}
+	tests := []struct {
+		name      string
+		prAuthors map[string]string
+		want      []commenterCount
+	}{
+		{
+			"all comments",
+			nil,
+			[]commenterCount{{"bob", "2024-01", 3, 2}, {"alice", "2024-01", 1, 1}, {"Alice", "2024-02", 1, 1}, {"bob", "2024-02", 1, 1}},
+		},
+		{
+			"excluding comments on own PRs",
+			map[string]string{"jenkinsci/jenkins/1": "alice", "jenkinsci/jenkins/2": "bob"},
+			[]commenterCount{{"bob", "2024-01", 2, 1}, {"alice", "2024-01", 1, 1}, {"bob", "2024-02", 1, 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countCommentsPerCommenter(comments, tt.prAuthors); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("countCommentsPerCommenter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_aggregateCommentersCommand_integrationTest(t *testing.T) {
+	// Setup environment
+	tempDir := t.TempDir()
+	dataFilename, err := duplicateFile("../test-data/monthly/commenters-2024-01.csv", tempDir, false)
+	assert.NoError(t, err, "Unexpected data file duplication error")
+
+	actual := new(bytes.Buffer)
+	rootCmd.SetOut(actual)
+	rootCmd.SetErr(actual)
+	rootCmd.SetArgs([]string{"aggregate", "commenters", dataFilename, "--out=", "--format=csv", "--exclude_own", "--submissions=../test-data/monthly/submissions-2024-01.csv"})
+
+	// execute command
+	error := rootCmd.Execute()
+
+	// check results
+	assert.NoError(t, error, "Call should not have failed")
+	table, err := loadCSVtable(filepath.Join(tempDir, "comments_per_commenter-2024-01.csv"))
+	assert.NoError(t, err, "Generated file could not be loaded")
+	assert.Equal(t, schemaCommentsCount, table.schema.kind)
+	assert.Equal(t, [][]string{{"bob", "2024-01", "3", "2"}, {"carol", "2024-01", "1", "1"}}, table.records)
+}
+
+func Test_aggregateCommentersCommand_excludeOwnRequiresSubmissions(t *testing.T) {
+	actual := new(bytes.Buffer)
+	rootCmd.SetOut(actual)
+	rootCmd.SetErr(actual)
+	// flag values are persistent between test executions
+	aggregateSubmissionsFileNames = nil
+	rootCmd.SetArgs([]string{"aggregate", "commenters", "../test-data/monthly/commenters-2024-01.csv", "--exclude_own"})
+
+	error := rootCmd.Execute()
+
+	assert.ErrorContains(t, error, "requires the submissions files")
+}
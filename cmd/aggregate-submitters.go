@@ -0,0 +1,160 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var aggregateIsMergedOnly bool
+var aggregateIsByRepo bool
+
+// aggregateSubmittersCmd represents the "aggregate submitters" command
+var aggregateSubmittersCmd = &cobra.Command{
+	Use:   "submitters <submissions CSV>",
+	Short: "Computes the number of PRs per submitter",
+	Long: `Computes the number of PRs per submitter from a submissions file generated by
+"get submitters".
+
+The output is the "pr_per_submitter" file ("user,PR") that is used by the "honor" command.
+With "--by_repo", the count is broken down per repository ("user,repository,PR").
+
+If not specified, the output file name is derived from the input file name:
+"data/submissions-2024-04.csv" gives "data/pr_per_submitter-2024-04.csv" (or
+"data/pr_per_submitter_per_repo-2024-04.csv" with "--by_repo").
+Use "-" as input file name to read from the standard input.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if err := cobra.MinimumNArgs(1)(cmd, args); err != nil {
+			return err
+		}
+		if !fileExist(args[0]) && !isStdStream(args[0]) {
+			return fmt.Errorf("ERROR: %s is not an existing file.\n", args[0])
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return performAggregateSubmitters(args[0], aggregateOutputFileName, aggregateIsMergedOnly, aggregateIsByRepo)
+	},
+}
+
+func init() {
+	aggregateCmd.AddCommand(aggregateSubmittersCmd)
+
+	aggregateSubmittersCmd.Flags().BoolVarP(&aggregateIsMergedOnly, "merged_only", "", false, "Count only the merged PRs.")
+	aggregateSubmittersCmd.Flags().BoolVarP(&aggregateIsByRepo, "by_repo", "", false, "Break down the count per repository.")
+}
+
+// Number of PRs of a submitter (for a repository if broken down)
+type submitterPRcount struct {
+	user       string
+	repository string
+	count      int
+}
+
+// Main function of the "aggregate submitters" command
+func performAggregateSubmitters(inputFileName string, outputFileName string, isMergedOnly bool, isByRepo bool) error {
+	submissions, err := loadSubmissions(inputFileName)
+	if err != nil {
+		return err
+	}
+
+	schema := currentSchema(schemaPrPerSubmitter)
+	if isByRepo {
+		schema = currentSchema(schemaPrPerRepo)
+	}
+
+	if outputFileName == "" {
+		outputFileName, err = computeAggregateOutputFileName(inputFileName, schema.kind)
+		if err != nil {
+			return err
+		}
+	}
+
+	counts := countPRsPerSubmitter(submissions, isMergedOnly, isByRepo)
+
+	var outputLines []string
+	for _, count := range counts {
+		if isByRepo {
+			outputLines = append(outputLines, formatCSVrecord([]string{count.user, count.repository, strconv.Itoa(count.count)}, schema.separator))
+		} else {
+			outputLines = append(outputLines, formatCSVrecord([]string{count.user, strconv.Itoa(count.count)}, schema.separator))
+		}
+	}
+
+	// Creates or overwrites the output file
+	if err := writeOutputFile(outputFileName, false, schema.headerLine(), outputLines); err != nil {
+		return err
+	}
+
+	if isVerbose {
+		fmt.Fprintf(os.Stderr, "Aggregated %d PRs in %d record(s) (\"%s\")\n", len(submissions), len(counts), outputFileName)
+	}
+	return nil
+}
+
+// Counts the PRs per submitter (and repository if requested).
+// The result is sorted by decreasing count, then by user and repository name.
+func countPRsPerSubmitter(submissions []submissionRecord, isMergedOnly bool, isByRepo bool) []submitterPRcount {
+	countIndex := make(map[string]int)
+	var counts []submitterPRcount
+
+	for _, submission := range submissions {
+		// Skip PRs from deleted users (no login available)
+		if submission.user == "" {
+			continue
+		}
+		if isMergedOnly && !submission.isMerged() {
+			continue
+		}
+
+		key := submission.user
+		repository := ""
+		if isByRepo {
+			repository = submission.repositorySpec()
+			key = key + " " + repository
+		}
+
+		if i, exists := countIndex[key]; exists {
+			counts[i].count++
+		} else {
+			countIndex[key] = len(counts)
+			counts = append(counts, submitterPRcount{user: submission.user, repository: repository, count: 1})
+		}
+	}
+
+	sort.SliceStable(counts, func(i, j int) bool {
+		if counts[i].count != counts[j].count {
+			return counts[i].count > counts[j].count
+		}
+		if !strings.EqualFold(counts[i].user, counts[j].user) {
+			return strings.ToLower(counts[i].user) < strings.ToLower(counts[j].user)
+		}
+		return counts[i].repository < counts[j].repository
+	})
+	return counts
+}
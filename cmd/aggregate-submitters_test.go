@@ -0,0 +1,98 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_countPRsPerSubmitter(t *testing.T) {
+	submissions := []submissionRecord{
+		{org: "jenkinsci", repository: "jenkins", number: "1", user: "bob", mergedAt: "2024-01-02T00:00:00Z"},
+		{org: "jenkinsci", repository: "git-plugin", number: "2", user: "alice"},
+		{org: "jenkinsci", repository: "jenkins", number: "3", user: "alice", mergedAt: "2024-01-02T00:00:00Z"},
+		{org: "jenkinsci", repository: "jenkins", number: "4", user: "Bob", mergedAt: "2024-01-02T00:00:00Z"},
+		{org: "jenkinsci", repository: "jenkins", number: "5", user: ""},
+		{org: "jenkinsci", repository: "jenkins", number: "6", user: "alice", mergedAt: "2024-01-02T00:00:00Z"},
+	}
+	tests := []struct {
+		name         string
+		isMergedOnly bool
+		isByRepo     bool
+		want         []submitterPRcount
+	}{
+		{
+			"all PRs",
+			false, false,
+			[]submitterPRcount{{"alice", "", 3}, {"bob", "", 1}, {"Bob", "", 1}},
+		},
+		{
+			"merged only",
+			true, false,
+			[]submitterPRcount{{"alice", "", 2}, {"bob", "", 1}, {"Bob", "", 1}},
+		},
+		{
+			"by repository",
+			false, true,
+			[]submitterPRcount{{"alice", "jenkinsci/jenkins", 2}, {"alice", "jenkinsci/git-plugin", 1}, {"bob", "jenkinsci/jenkins", 1}, {"Bob", "jenkinsci/jenkins", 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countPRsPerSubmitter(submissions, tt.isMergedOnly, tt.isByRepo); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("countPRsPerSubmitter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_aggregateSubmittersCommand_integrationTest(t *testing.T) {
+	// Setup environment
+	tempDir := t.TempDir()
+	dataFilename, err := duplicateFile("../test-data/monthly/submissions-2024-01.csv", tempDir, false)
+	assert.NoError(t, err, "Unexpected data file duplication error")
+
+	actual := new(bytes.Buffer)
+	rootCmd.SetOut(actual)
+	rootCmd.SetErr(actual)
+	rootCmd.SetArgs([]string{"aggregate", "submitters", dataFilename, "--out="})
+
+	// execute command
+	error := rootCmd.Execute()
+
+	// check results: the generated file must be usable by the "honor" command
+	assert.NoError(t, error, "Call should not have failed")
+	table, err := loadCSVtable(filepath.Join(tempDir, "pr_per_submitter-2024-01.csv"))
+	assert.NoError(t, err, "Generated file could not be loaded")
+	assert.Equal(t, schemaPrPerSubmitter, table.schema.kind)
+	assert.Equal(t, [][]string{{"alice", "3"}, {"bob", "1"}, {"carol", "1"}}, table.records)
+}
+
+func Test_performAggregateSubmitters_invalidInput(t *testing.T) {
+	err := performAggregateSubmitters("../test-data/pr_per_submitter-2024-04.csv", filepath.Join(t.TempDir(), "out.csv"), false, false)
+	assert.Error(t, err, "Aggregating a non submissions file should fail")
+}
@@ -0,0 +1,67 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+
+	"github.com/spf13/cobra"
+)
+
+var aggregateOutputFileName string
+
+// aggregateCmd represents the aggregate command
+var aggregateCmd = &cobra.Command{
+	Use:   "aggregate [submitters|commenters]",
+	Short: "Computes aggregated data (per user) from extracted data",
+	Long: `Computes aggregated data from the files generated by the "get" commands.
+
+The generated files are the ones consumed by the other commands (such as "honor") or
+used to publish the community statistics.
+`,
+}
+
+// Cobra initialize
+func init() {
+	rootCmd.AddCommand(aggregateCmd)
+
+	aggregateCmd.PersistentFlags().StringVarP(&aggregateOutputFileName, "out", "o", "", "Output file name (\"-\" to write to the standard output). Default is derived from the input file name.")
+}
+
+var monthlyDataFile_regexp = regexp.MustCompile(`^[a-z_]+-(20[12][0-9]-(0[1-9]|1[0-2]))\.csv$`)
+
+// Computes the output file name from the input file name, following the data directory naming
+// convention: "submissions-2024-04.csv" gives "pr_per_submitter-2024-04.csv" in the same directory.
+// When reading from the standard input, data is written to the standard output.
+func computeAggregateOutputFileName(inputFileName string, outputPrefix string) (string, error) {
+	if isStdStream(inputFileName) {
+		return stdStreamName, nil
+	}
+
+	matches := monthlyDataFile_regexp.FindStringSubmatch(filepath.Base(inputFileName))
+	if matches == nil {
+		return "", fmt.Errorf("Unable to derive the output file name from \"%s\": use the \"--out\" flag", inputFileName)
+	}
+	return filepath.Join(filepath.Dir(inputFileName), outputPrefix+"-"+matches[1]+".csv"), nil
+}
@@ -0,0 +1,55 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func Test_computeAggregateOutputFileName(t *testing.T) {
+	tests := []struct {
+		name          string
+		inputFileName string
+		outputPrefix  string
+		want          string
+		wantErr       bool
+	}{
+		{"data directory convention", "data/submissions-2024-04.csv", "pr_per_submitter", filepath.Join("data", "pr_per_submitter-2024-04.csv"), false},
+		{"current directory", "submissions-2023-12.csv", "pr_per_submitter_per_repo", "pr_per_submitter_per_repo-2023-12.csv", false},
+		{"standard input", "-", "pr_per_submitter", "-", false},
+		{"no month in file name", "data/my_submissions.csv", "pr_per_submitter", "", true},
+		{"invalid month in file name", "data/submissions-2024-13.csv", "pr_per_submitter", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := computeAggregateOutputFileName(tt.inputFileName, tt.outputPrefix)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("computeAggregateOutputFileName() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("computeAggregateOutputFileName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// The prefix identifying a GitHub node ID in the alias file
+const nodeIDprefix = "id:"
+
+// Maps the different GitHub accounts (or former handles) of a person to a single canonical login
+type identityAliases struct {
+	canonicalByLogin  map[string]string // the key is the lower case alias login
+	canonicalByNodeID map[string]string
+}
+
+// The identity aliases of the run (see the "--aliases" flag)
+var userAliases identityAliases
+
+// Loads an alias file. The format is similar to a git mailmap file: each line starts with
+// the canonical login, followed by the other logins (or node IDs, prefixed with "id:") of the
+// same person, separated by white spaces. Comments start with "#".
+//
+//	jdoe       john-doe-old  jdoe-work
+//	jdoe       id:MDQ6VXNlcjEyMzQ1
+func loadIdentityAliases(fileName string) (identityAliases, error) {
+	aliases := identityAliases{
+		canonicalByLogin:  make(map[string]string),
+		canonicalByNodeID: make(map[string]string),
+	}
+
+	f, err := os.Open(fileName)
+	if err != nil {
+		return aliases, fmt.Errorf("Unable to read alias file %s: %v", fileName, err)
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return aliases, fmt.Errorf("Error loading \"%s\": %v", fileName, err)
+	}
+
+	for _, line := range removeComments(lines) {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !isValidOrgFormat(fields[0]) {
+			return aliases, fmt.Errorf("Invalid alias line \"%s\" in \"%s\" (expecting \"<canonical login> <alias>...\")", line, fileName)
+		}
+		canonical := fields[0]
+		for _, alias := range fields[1:] {
+			if nodeID, isNodeID := strings.CutPrefix(alias, nodeIDprefix); isNodeID {
+				aliases.canonicalByNodeID[nodeID] = canonical
+				continue
+			}
+			if !isValidOrgFormat(alias) {
+				return aliases, fmt.Errorf("Invalid alias \"%s\" in \"%s\"", alias, fileName)
+			}
+			if existing, exists := aliases.canonicalByLogin[strings.ToLower(alias)]; exists && !strings.EqualFold(existing, canonical) {
+				return aliases, fmt.Errorf("\"%s\" is an alias of both \"%s\" and \"%s\" in \"%s\"", alias, existing, canonical, fileName)
+			}
+			aliases.canonicalByLogin[strings.ToLower(alias)] = canonical
+		}
+	}
+	return aliases, nil
+}
+
+// Returns the canonical login of a user (the login itself if it has no alias)
+func (a identityAliases) canonical(login string) string {
+	if canonical, exists := a.canonicalByLogin[strings.ToLower(login)]; exists {
+		return canonical
+	}
+	return login
+}
+
+// Returns the canonical login of a user, using first its node ID (stable across renames) if known
+func (a identityAliases) canonicalWithNodeID(login string, nodeID string) string {
+	if canonical, exists := a.canonicalByNodeID[nodeID]; exists && nodeID != "" {
+		return canonical
+	}
+	return a.canonical(login)
+}
+
+// Returns true if both logins belong to the same person (case insensitive)
+func (a identityAliases) isSameIdentity(login1 string, login2 string) bool {
+	return strings.EqualFold(a.canonical(login1), a.canonical(login2))
+}
+
+// Returns all the known logins of the persons of the list (the logins themselves, their
+// canonical logins and their sorted aliases)
+func (a identityAliases) expandLogins(logins []string) []string {
+	var expanded []string
+	isAdded := make(map[string]bool)
+	add := func(login string) {
+		if !isAdded[strings.ToLower(login)] {
+			isAdded[strings.ToLower(login)] = true
+			expanded = append(expanded, login)
+		}
+	}
+
+	// The aliases are sorted to always return them in the same order
+	var aliases []string
+	for alias := range a.canonicalByLogin {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	for _, login := range logins {
+		add(login)
+		canonical := a.canonical(login)
+		add(canonical)
+		for _, alias := range aliases {
+			if strings.EqualFold(a.canonicalByLogin[alias], canonical) {
+				add(alias)
+			}
+		}
+	}
+	return expanded
+}
@@ -0,0 +1,133 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_loadIdentityAliases(t *testing.T) {
+	aliases, err := loadIdentityAliases("../test-data/aliases.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"alice-old": "alice", "alice-work": "alice", "erin": "dave", "grace-ci": "grace"}, aliases.canonicalByLogin)
+	assert.Equal(t, map[string]string{"MDQ6VXNlcjEwMDQ=": "dave"}, aliases.canonicalByNodeID)
+
+	_, err = loadIdentityAliases("../test-data/invalid-aliases.txt")
+	assert.ErrorContains(t, err, "is an alias of both")
+
+	_, err = loadIdentityAliases("../test-data/test-exclusion.txt")
+	assert.ErrorContains(t, err, "Invalid alias line")
+
+	_, err = loadIdentityAliases("../test-data/inexistent.txt")
+	assert.Error(t, err)
+}
+
+func Test_identityAliases_canonical(t *testing.T) {
+	aliases, err := loadIdentityAliases("../test-data/aliases.txt")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		login  string
+		nodeID string
+		want   string
+	}{
+		{"alias", "Alice-Old", "", "alice"},
+		{"canonical", "alice", "", "alice"},
+		{"no alias", "bob", "", "bob"},
+		{"known node ID", "dave-renamed", "MDQ6VXNlcjEwMDQ=", "dave"},
+		{"unknown node ID", "alice-work", "MDQ6VXNlcjk5OTk=", "alice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, aliases.canonicalWithNodeID(tt.login, tt.nodeID))
+		})
+	}
+
+	assert.True(t, aliases.isSameIdentity("ALICE-WORK", "alice-old"))
+	assert.False(t, aliases.isSameIdentity("alice", "bob"))
+	assert.True(t, identityAliases{}.isSameIdentity("Bob", "bob"), "Without aliases, the logins are compared case insensitively")
+}
+
+func Test_identityAliases_expandLogins(t *testing.T) {
+	aliases, err := loadIdentityAliases("../test-data/aliases.txt")
+	assert.NoError(t, err)
+
+	got := aliases.expandLogins([]string{"alice-old", "bob"})
+
+	assert.Equal(t, []string{"alice-old", "alice", "alice-work", "bob"}, got, "The order should be stable")
+}
+
+func Test_isExcludedAuthor_withAliases(t *testing.T) {
+	var err error
+	userAliases, err = loadIdentityAliases("../test-data/aliases.txt")
+	assert.NoError(t, err)
+	defer func() { userAliases = identityAliases{} }()
+
+	assert.True(t, isExcludedAuthor([]string{"alice"}, "alice-work"))
+	assert.False(t, isExcludedAuthor([]string{"alice"}, "bob"))
+}
+
+func Test_loadSubmissions_withAliases(t *testing.T) {
+	var err error
+	userAliases, err = loadIdentityAliases("../test-data/aliases.txt")
+	assert.NoError(t, err)
+	defer func() { userAliases = identityAliases{} }()
+
+	submissions, err := loadSubmissions("../test-data/monthly/submissions-2024-03.csv")
+
+	assert.NoError(t, err)
+	for _, submission := range submissions {
+		assert.NotEqual(t, "erin", submission.user, "Aliases should be replaced by the canonical login")
+	}
+}
+
+func Test_removeCommand_withAliases(t *testing.T) {
+	tempDir := t.TempDir()
+	tempFileName, err := duplicateFile("../test-data/aliased-submission-list.csv", tempDir, false)
+	assert.NoError(t, err, "Unexpected File duplication error")
+
+	actual := new(bytes.Buffer)
+	rootCmd.SetOut(actual)
+	rootCmd.SetErr(actual)
+	rootCmd.SetArgs([]string{"remove", "grace", tempFileName, "--aliases=../test-data/aliases.txt", "--backup=false"})
+
+	error := rootCmd.Execute()
+	// restore the defaults for the other tests
+	aliasFileName = ""
+	userAliases = identityAliases{}
+	remove_requireBackup = true
+	excludedGithubUsers = nil
+
+	assert.NoError(t, error, "Function should not have failed")
+	content, err := os.ReadFile(tempFileName)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(content), "\"grace\"")
+	assert.NotContains(t, string(content), "Grace-CI", "The aliases should have been removed too")
+	assert.Contains(t, string(content), "gracious")
+	assert.Equal(t, 3, len(strings.Split(strings.TrimSpace(string(content)), "\n")))
+}
@@ -0,0 +1,169 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// The output formats supported by the aggregation and report commands
+const (
+	formatCSV      = "csv"
+	formatJSON     = "json"
+	formatMarkdown = "md"
+	formatHTML     = "html"
+)
+
+// Returns the usual file extension for the output format
+func outputFormatExtension(format string) string {
+	return "." + format
+}
+
+// Checks that the requested output format is one of the supported ones
+func validateOutputFormat(format string, supportedFormats []string) error {
+	for _, supported := range supportedFormats {
+		if format == supported {
+			return nil
+		}
+	}
+	return fmt.Errorf("Unsupported output format \"%s\" (should be one of %s)", format, prettyPrintStringList(supportedFormats))
+}
+
+// Writes a table (header and rows) to the output file ("-" for the standard output) in the requested format.
+// The numeric columns (integers or decimals) are the ones written as numbers in JSON.
+func writeTableOutput(outputFileName string, format string, header []string, numericColumns []string, rows [][]string) error {
+	var lines []string
+	outputHeader := ""
+	isNoHeader := true
+
+	switch format {
+	case formatCSV:
+		outputHeader = strings.Join(header, ",")
+		isNoHeader = false
+		for _, row := range rows {
+			lines = append(lines, formatCSVrecord(row, ","))
+		}
+	case formatJSON:
+		jsonOutput, err := formatTableAsJSON(header, numericColumns, rows)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, jsonOutput)
+	case formatMarkdown:
+		lines = formatTableAsMarkdown(header, rows)
+	default:
+		return fmt.Errorf("Unsupported output format \"%s\"", format)
+	}
+
+	// Creates or overwrites the output file
+	return writeOutputFile(outputFileName, isNoHeader, outputHeader, lines)
+}
+
+// Formats a table as a JSON array of objects (one per row, keyed by the header's column names).
+// The values of the numeric columns are written as JSON numbers (null if empty), the other values
+// as strings, whatever they look like. The column order is preserved.
+func formatTableAsJSON(header []string, numericColumns []string, rows [][]string) (string, error) {
+	isNumericColumn := make(map[string]bool)
+	for _, column := range numericColumns {
+		isNumericColumn[column] = true
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString("[")
+	for i, row := range rows {
+		if i > 0 {
+			buffer.WriteString(",")
+		}
+		buffer.WriteString("\n  {")
+		for j, column := range header {
+			if j > 0 {
+				buffer.WriteString(", ")
+			}
+			key, err := json.Marshal(column)
+			if err != nil {
+				return "", err
+			}
+			value := ""
+			if j < len(row) {
+				value = row[j]
+			}
+			buffer.Write(key)
+			buffer.WriteString(": ")
+			encodedValue, err := encodeJSONvalue(value, isNumericColumn[column])
+			if err != nil {
+				return "", err
+			}
+			buffer.Write(encodedValue)
+		}
+		buffer.WriteString("}")
+	}
+	if len(rows) > 0 {
+		buffer.WriteString("\n")
+	}
+	buffer.WriteString("]")
+	return buffer.String(), nil
+}
+
+// Encodes a table value in JSON: a number (or null if empty) for a numeric column,
+// a string otherwise (or if the value is not a number)
+func encodeJSONvalue(value string, isNumeric bool) ([]byte, error) {
+	if isNumeric {
+		if value == "" {
+			return []byte("null"), nil
+		}
+		if number, err := strconv.Atoi(value); err == nil {
+			return []byte(strconv.Itoa(number)), nil
+		}
+		if number, err := strconv.ParseFloat(value, 64); err == nil && !math.IsNaN(number) && !math.IsInf(number, 0) {
+			return json.Marshal(number)
+		}
+	}
+	return json.Marshal(value)
+}
+
+// Formats a table as a Markdown table (one line per row, header included).
+// The "|" characters in values are escaped.
+func formatTableAsMarkdown(header []string, rows [][]string) []string {
+	escape := func(values []string) string {
+		var escaped []string
+		for _, value := range values {
+			escaped = append(escaped, strings.ReplaceAll(value, "|", "\\|"))
+		}
+		return "| " + strings.Join(escaped, " | ") + " |"
+	}
+
+	var separators []string
+	for range header {
+		separators = append(separators, "---")
+	}
+
+	lines := []string{escape(header), "|" + strings.Join(separators, "|") + "|"}
+	for _, row := range rows {
+		lines = append(lines, escape(row))
+	}
+	return lines
+}
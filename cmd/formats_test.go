@@ -0,0 +1,121 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_validateOutputFormat(t *testing.T) {
+	assert.NoError(t, validateOutputFormat("csv", []string{formatCSV, formatJSON}))
+	assert.NoError(t, validateOutputFormat("json", []string{formatCSV, formatJSON}))
+	assert.Error(t, validateOutputFormat("xml", []string{formatCSV, formatJSON}))
+	assert.Error(t, validateOutputFormat("", []string{formatCSV}))
+}
+
+func Test_formatTableAsJSON(t *testing.T) {
+	tests := []struct {
+		name           string
+		header         []string
+		numericColumns []string
+		rows           [][]string
+		want           string
+	}{
+		{
+			"typical table",
+			[]string{"user", "PR"},
+			[]string{"PR"},
+			[][]string{{"basil", "69"}, {"gounthar", "040"}},
+			"[\n  {\"user\": \"basil\", \"PR\": 69},\n  {\"user\": \"gounthar\", \"PR\": 40}\n]",
+		},
+		{
+			"numeric values of text columns stay strings",
+			[]string{"user", "title", "PR"},
+			[]string{"PR"},
+			[][]string{{"1234", "2024", "3"}, {"basil", "Fix NPE", ""}},
+			"[\n  {\"user\": \"1234\", \"title\": \"2024\", \"PR\": 3},\n  {\"user\": \"basil\", \"title\": \"Fix NPE\", \"PR\": null}\n]",
+		},
+		{
+			"decimal values and empty cells of numeric columns",
+			[]string{"repository", "merge_rate", "median_hours"},
+			[]string{"merge_rate", "median_hours"},
+			[][]string{{"jenkinsci/foo", "66.7", "12.5"}, {"jenkinsci/bar", "0", ""}, {"jenkinsci/baz", "NaN", "n/a"}},
+			"[\n  {\"repository\": \"jenkinsci/foo\", \"merge_rate\": 66.7, \"median_hours\": 12.5},\n" +
+				"  {\"repository\": \"jenkinsci/bar\", \"merge_rate\": 0, \"median_hours\": null},\n" +
+				"  {\"repository\": \"jenkinsci/baz\", \"merge_rate\": \"NaN\", \"median_hours\": \"n/a\"}\n]",
+		},
+		{
+			"empty table",
+			[]string{"user", "PR"},
+			[]string{"PR"},
+			nil,
+			"[]",
+		},
+		{
+			"special characters and short row",
+			[]string{"title", "month"},
+			nil,
+			[][]string{{"say \"hi\""}},
+			"[\n  {\"title\": \"say \\\"hi\\\"\", \"month\": \"\"}\n]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatTableAsJSON(tt.header, tt.numericColumns, tt.rows)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_writeTableOutput(t *testing.T) {
+	tempDir := t.TempDir()
+	header := []string{"user", "PR"}
+	rows := [][]string{{"basil", "69"}}
+
+	csvFileName := filepath.Join(tempDir, "out.csv")
+	assert.NoError(t, writeTableOutput(csvFileName, formatCSV, header, []string{"PR"}, rows))
+	content, _ := os.ReadFile(csvFileName)
+	assert.Equal(t, "user,PR\n\"basil\",69\n", string(content))
+
+	jsonFileName := filepath.Join(tempDir, "out.json")
+	assert.NoError(t, writeTableOutput(jsonFileName, formatJSON, header, []string{"PR"}, rows))
+	content, _ = os.ReadFile(jsonFileName)
+	assert.Equal(t, "[\n  {\"user\": \"basil\", \"PR\": 69}\n]\n", string(content))
+
+	assert.Error(t, writeTableOutput(csvFileName, "xml", header, nil, rows), "Unsupported format should fail")
+}
+
+func Test_formatTableAsMarkdown(t *testing.T) {
+	got := formatTableAsMarkdown([]string{"user", "title"}, [][]string{{"basil", "a | b"}})
+
+	want := []string{
+		"| user | title |",
+		"|---|---|",
+		"| basil | a \\| b |",
+	}
+	assert.Equal(t, want, got)
+}
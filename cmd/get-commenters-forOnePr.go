@@ -25,6 +25,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
 	"time"
 
 	"github.com/shurcooL/githubv4"
@@ -81,7 +82,7 @@ retrieved from an environment variable (default is "GITHUB_TOKEN" but can be ove
 		}
 
 		if isRootDebug || isDebugGet {
-			fmt.Println("*** Debug mode enabled ***\nSee \"debug.log\" for the trace")
+			fmt.Fprintln(os.Stderr, "*** Debug mode enabled ***\nSee \"debug.log\" for the trace")
 		}
 
 		getCommenters(args[0], globalIsAppend, globalIsNoHeader, outputFileName)
@@ -110,12 +111,12 @@ func getCommenters(prSpec string, isAppend bool, isNoHeader bool, outputFileName
 
 	org, prj, pr, err := validatePRspec(prSpec)
 	if err != nil {
-		fmt.Printf("Unexpected error in PR specification (%v)\n Skipping %s\n", err, prSpec)
+		fmt.Fprintf(os.Stderr, "Unexpected error in PR specification (%v)\n Skipping %s\n", err, prSpec)
 		return 0
 	}
 
 	if isVerbose {
-		fmt.Printf("Fetching comments for %s\n", prSpec)
+		fmt.Fprintf(os.Stderr, "Fetching comments for %s\n", prSpec)
 	}
 
 	if isDebugGet {
@@ -129,16 +130,19 @@ func getCommenters(prSpec string, isAppend bool, isNoHeader bool, outputFileName
 	if nbrOfComments > 0 {
 
 		// Creates, overwrites, or opens for append depending on the combination
-		out, newIsNoHeader := openOutputCSV(outputFileName, isAppend, isNoHeader)
-		defer out.Close()
+		out, newIsNoHeader, err := openOutputCSV(outputFileName, isAppend, isNoHeader)
+		if err != nil {
+			log.Fatal(err)
+		}
 
-		//TODO: Refactor
-		header := "PR_ref,commenter,month"
+		header := currentSchema(schemaCommenters).headerLine()
 		writeCSVtoFile(out, isAppend, newIsNoHeader, header, output_data_list)
-		out.Close()
+		if err := out.Close(); err != nil {
+			log.Fatal(err)
+		}
 	} else {
 		if isVerbose {
-			fmt.Println("   No comments found for PR, skipping...")
+			fmt.Fprintln(os.Stderr, "   No comments found for PR, skipping...")
 		}
 	}
 	return nbrOfComments
@@ -199,6 +203,9 @@ var prQuery2 struct {
 					Author    struct {
 						Login string
 						Url   string
+						User  struct {
+							Id string
+						} `graphql:"... on User"`
 					}
 				}
 			} `graphql:"comments(first: 100)"`
@@ -209,6 +216,9 @@ var prQuery2 struct {
 					Author    struct {
 						Login string
 						Url   string
+						User  struct {
+							Id string
+						} `graphql:"... on User"`
 					}
 					Comments struct {
 						Nodes []struct {
@@ -217,6 +227,9 @@ var prQuery2 struct {
 							Author    struct {
 								Login string
 								Url   string
+								User  struct {
+									Id string
+								} `graphql:"... on User"`
 							}
 						}
 					} `graphql:"comments(first: 100)"`
@@ -273,6 +286,7 @@ func fetchComments_v4(org string, prj string, pr int) (nbrComment int, output []
 		if author == "" {
 			author = "deleted_user"
 		}
+		author = userAliases.canonicalWithNodeID(author, comment.Author.User.Id)
 
 		// exclude bots
 		if isUserBot(comment.Author.Url) {
@@ -295,6 +309,7 @@ func fetchComments_v4(org string, prj string, pr int) (nbrComment int, output []
 		if author == "" {
 			author = "deleted_user"
 		}
+		author = userAliases.canonicalWithNodeID(author, comment.Author.User.Id)
 
 		// exclude bots
 		if isUserBot(comment.Author.Url) {
@@ -315,6 +330,7 @@ func fetchComments_v4(org string, prj string, pr int) (nbrComment int, output []
 			if author == "" {
 				author = "deleted_user"
 			}
+			author = userAliases.canonicalWithNodeID(author, comment.Author.User.Id)
 
 			// exclude bots
 			if isUserBot(comment.Author.Url) {
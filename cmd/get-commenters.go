@@ -22,14 +22,10 @@ THE SOFTWARE.
 package cmd
 
 import (
-	"encoding/csv"
 	"fmt"
 	"log"
 	"os"
 
-	"regexp"
-	"strings"
-
 	//See https://github.com/schollz/progressbar
 	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/cobra"
@@ -46,12 +42,17 @@ The CSV list of PRs must be in the form of \"org,repository,number,url,state,cre
 Such a CSV is generated by the jenkins submitter extractions tool (\"jenkins-contribution-extractor get submitters\").
 
 To extract the commenters for a single PR, use the "forPR" sub-command. 
+
+Use "-" as filename to read the list of PRs from the standard input. Together with "-o -", 
+it allows to chain commands: 
+  jenkins-contribution-extractor get submitters jenkinsci 2024-04 -o - | jenkins-contribution-extractor get commenters - -o -
+Progress bars and messages are written to the standard error.
 `,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if err := cobra.MinimumNArgs(1)(cmd, args); err != nil {
 			return err
 		}
-		if !fileExist(args[0]) {
+		if !fileExist(args[0]) && !isStdStream(args[0]) {
 			return fmt.Errorf("Invalid file\n")
 		}
 
@@ -74,7 +75,7 @@ To extract the commenters for a single PR, use the "forPR" sub-command.
 		}
 
 		if isRootDebug {
-			fmt.Print("*** Debug mode enabled ***\nSee \"debug.log\" for the trace\n\n")
+			fmt.Fprint(os.Stderr, "*** Debug mode enabled ***\nSee \"debug.log\" for the trace\n\n")
 
 			limit, remaining, _, _ := get_quota_data_v4()
 			loggers.debug.Printf("Start quota: %d/%d\n", remaining, limit)
@@ -94,91 +95,52 @@ func init() {
 
 }
 
-var referenceSubmitterCSVheader = []string{"org", "repository", "number", "url", "state", "created_at", "merged_at", "user.login", "month_year", "title"}
-
 // Loads the data from a file and try to parse it as a CSV
 func loadPrListFile(fileName string, isVerbose bool) ([]string, bool) {
 
-	f, err := os.Open(fileName)
-	if err != nil {
-		log.Printf("Unable to read input file "+fileName+"\n", err)
-		return nil, false
+	if isVerbose {
+		fmt.Fprintln(os.Stderr, "Checking input file")
 	}
-	defer f.Close()
 
-	r := csv.NewReader(f)
-
-	headerLine, err1 := r.Read()
-	if err1 != nil {
-		log.Printf("Unexpected error loading"+fileName+"\n", err)
+	table, err := loadCSVtable(fileName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, " Error: %v\n", err)
 		return nil, false
 	}
 
-	if isVerbose {
-		fmt.Println("Checking input file")
-	}
-
-	if !validateHeader(headerLine, referenceSubmitterCSVheader, isVerbose) {
-		fmt.Println(" Error: header is incorrect.")
+	if table.schema.kind != schemaSubmitters {
+		fmt.Fprintf(os.Stderr, " Error: header is incorrect (\"%s\" file instead of \"%s\").\n", table.schema.kind, schemaSubmitters)
 		return nil, false
 	} else {
 		if isVerbose {
-			fmt.Printf("  - Header is correct\n")
+			fmt.Fprintf(os.Stderr, "  - Header is correct (%s v%d)\n", table.schema.kind, table.schema.version)
 		}
 	}
 
-	records, err := r.ReadAll()
-	if err != nil {
-		log.Printf("Unexpected error loading \""+fileName+"\"\n", err)
-		return nil, false
-	}
+	records := table.records
 
 	if len(records) < 1 {
-		fmt.Printf("Error: No data available after the header\n")
+		fmt.Fprintf(os.Stderr, "Error: No data available after the header\n")
 		return nil, true
 	}
 	if isVerbose {
-		fmt.Println("  - At least one Pull Request data available")
+		fmt.Fprintln(os.Stderr, "  - At least one Pull Request data available")
 	}
 
 	var prList []string
-	prj_regexp, _ := regexp.Compile(`^[\w-\.]+$`) // see https://stackoverflow.com/questions/59081778/rules-for-special-characters-in-github-repository-name
-	pr_regexp, _ := regexp.Compile(`^\d+$`)
 
 	// Check the loaded data
 	for _, dataLine := range records {
 
-		org := dataLine[0]
-		if !isValidOrgFormat(org) {
+		org := table.get(dataLine, "org")
+		prj := table.get(dataLine, "repository")
+		prNbr := table.get(dataLine, "number")
+		if err := validatePRfields(org, prj, prNbr); err != nil {
 			if isVerbose {
-				fmt.Printf(" Error: ORG field \"%s\" doesn't seem to be a valid GitHub org.\n", org)
+				fmt.Fprintf(os.Stderr, " Error: %v\n", err)
 			}
 			if isRootDebug {
-				loggers.debug.Printf(" Error: ORG field \"%s\" doesn't seem to be a valid GitHub org.\n", org)
-			}
-			return nil, false
-		}
-
-		// project name must be "^[\w-\.]+$"
-		prj := dataLine[1]
-		if !prj_regexp.MatchString(strings.ToLower(prj)) {
-			if isVerbose {
-				fmt.Printf(" Error: PRJ field \"%s\" is not of the expected format", prj)
-			}
-			if isRootDebug {
-				loggers.debug.Printf(" Error: PRJ field \"%s\" is not of the expected format", prj)
-			}
-			return nil, false
-		}
-
-		// PR number must be a number
-		prNbr := dataLine[2]
-		if !pr_regexp.MatchString(prNbr) {
-			if isVerbose {
-				fmt.Printf(" Error: PR field \"%s\" is not a (positive) number", prNbr)
-			}
-			if isRootDebug {
-				loggers.debug.Printf(" Error: PR field \"%s\" is not a (positive) number", prNbr)
+				loggers.debug.Printf(" Error: %v\n", err)
 			}
 			return nil, false
 		}
@@ -189,7 +151,7 @@ func loadPrListFile(fileName string, isVerbose bool) ([]string, bool) {
 	}
 
 	if isVerbose {
-		fmt.Printf("Successfully loaded \"%s\" (%d Pull Request to analyze)\n\n", fileName, len(prList))
+		fmt.Fprintf(os.Stderr, "Successfully loaded \"%s\" (%d Pull Request to analyze)\n\n", fileName, len(prList))
 	}
 
 	return prList, true
@@ -201,7 +163,7 @@ func loadPrListFile(fileName string, isVerbose bool) ([]string, bool) {
 // This is where it happens
 func performAction(inputFile string) {
 
-	fmt.Printf("Processing \"%s\"\n", inputFile)
+	fmt.Fprintf(os.Stderr, "Processing \"%s\"\n", inputFile)
 	if isRootDebug {
 		loggers.debug.Printf("Processing \"%s\"\n", inputFile)
 	}
@@ -209,16 +171,14 @@ func performAction(inputFile string) {
 	// read the relevant data from the file (and checking it)
 	prList, result := loadPrListFile(inputFile, isVerbose)
 	if !result {
-		fmt.Printf("Could not load \"%s\"\n", inputFile)
+		fmt.Fprintf(os.Stderr, "Could not load \"%s\"\n", inputFile)
 		os.Exit(1)
 	}
 
 	isAppend := globalIsAppend
 	if !globalIsAppend {
 		// Meaning that we need to create a new file
-		if fileExist(outputFileName) {
-			os.Remove(outputFileName)
-		}
+		resetOutputFile(outputFileName)
 		isAppend = true
 	}
 
@@ -253,9 +213,9 @@ func performAction(inputFile string) {
 			}
 		}
 	}
-	fmt.Printf("Nbr of PR without comments: %d\n", nbrPR_noComment)
-	fmt.Printf("Nbr of PR with comments:    %d\n", nbrPR_withComments)
-	fmt.Printf("Total comments:             %d\n", totalComments)
+	fmt.Fprintf(os.Stderr, "Nbr of PR without comments: %d\n", nbrPR_noComment)
+	fmt.Fprintf(os.Stderr, "Nbr of PR with comments:    %d\n", nbrPR_withComments)
+	fmt.Fprintf(os.Stderr, "Total comments:             %d\n", totalComments)
 
 	if isRootDebug {
 		loggers.debug.Printf("Nbr of PR without comments: %d\n", nbrPR_noComment)
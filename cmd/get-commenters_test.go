@@ -23,6 +23,7 @@ package cmd
 
 import (
 	"bytes"
+	"os"
 	"reflect"
 	"strings"
 	"testing"
@@ -71,6 +72,22 @@ func Test_loadPrListFile(t *testing.T) {
 	}
 }
 
+func Test_loadPrListFile_stdin(t *testing.T) {
+	input, err := os.Open("../test-data/small-submission-list.csv")
+	assert.NoError(t, err, "Unexpected error opening test data")
+	defer input.Close()
+
+	savedStdin := os.Stdin
+	os.Stdin = input
+	defer func() { os.Stdin = savedStdin }()
+
+	got, ok := loadPrListFile("-", false)
+
+	assert.True(t, ok, "Loading from the standard input should succeed")
+	assert.Equal(t, 6, len(got))
+	assert.Equal(t, "jenkinsci/embeddable-build-status-plugin/229", got[0])
+}
+
 func Test_ExecuteGetCommenterProcessExcludeIfPresent(t *testing.T) {
 	actual := new(bytes.Buffer)
 	rootCmd.SetOut(actual)
@@ -40,7 +40,15 @@ var isSkipClosed bool
 var prCmd = &cobra.Command{
 	Use:   "submitters [org] [YYYY-MM]",
 	Short: "Get all PRs (and their submitters) for a given month and org.",
-	Long:  `Get all PRs (and their submitters) for a given month and org.`,
+	Long: `Get all PRs (and their submitters) for a given month and org.
+
+Besides the creation and merge dates, the closing date, the date of the first response
+(comment or review of another user than the author) and the date of the first approval
+are retrieved. They are used by the "report lifecycle" command.
+
+Only the first 20 comments and the first 20 reviews of each PR are examined: if they all
+come from the author, from excluded users or from bots, "first_response_at" (and
+"first_approval_at") is left empty even if another user responded later.`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		//call requires two parameters (org and month)
 		if err := cobra.MinimumNArgs(2)(cmd, args); err != nil {
@@ -93,7 +101,7 @@ func performSearch(searchedOrg string, searchedMonth string) error {
 	}
 
 	if isRootDebug {
-		fmt.Print("*** Debug mode enabled ***\nSee \"debug.log\" for the trace\n\n")
+		fmt.Fprint(os.Stderr, "*** Debug mode enabled ***\nSee \"debug.log\" for the trace\n\n")
 
 		limit, remaining, _, _ := get_quota_data_v4()
 		loggers.debug.Printf("Start quota: %d/%d\n", remaining, limit)
@@ -149,22 +157,23 @@ func performSearch(searchedOrg string, searchedMonth string) error {
 	isAppend := globalIsAppend
 	if !globalIsAppend {
 		// Meaning that we need to create a new file
-		if fileExist(outputFileName) {
-			os.Remove(outputFileName)
-		}
+		resetOutputFile(outputFileName)
 		isAppend = true
 	}
 
 	// We make no difference  whether data was found or not
 
 	// Creates, overwrites, or opens for append depending on the combination
-	out, newIsNoHeader := openOutputCSV(outputFileName, isAppend, globalIsNoHeader)
-	defer out.Close()
+	out, newIsNoHeader, err := openOutputCSV(outputFileName, isAppend, globalIsNoHeader)
+	if err != nil {
+		return err
+	}
 
-	//TODO: Refactor
-	header := "org,repository,number,url,state,created_at,merged_at,user.login,month_year,title"
+	header := currentSchema(schemaSubmitters).headerLine()
 	writeCSVtoFile(out, isAppend, newIsNoHeader, header, output_data_list)
-	out.Close()
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("Unable to write \"%s\": %v", outputFileName, err)
+	}
 
 	return nil
 }
@@ -210,13 +219,36 @@ func getData(searchedOrg string, startDate string, endDate string) ([]string, in
 							Author struct {
 								Login        string
 								ResourcePath string
+								User         struct {
+									Id string
+								} `graphql:"... on User"`
 							}
 							CreatedAt time.Time
 							MergedAt  time.Time
+							ClosedAt  time.Time
 							State     string
 							Url       string
 							Number    int
 							Title     string
+							Comments  struct {
+								Nodes []struct {
+									Author struct {
+										Login        string
+										ResourcePath string
+									}
+									CreatedAt time.Time
+								}
+							} `graphql:"comments(first: 20)"`
+							Reviews struct {
+								Nodes []struct {
+									Author struct {
+										Login        string
+										ResourcePath string
+									}
+									State       string
+									SubmittedAt time.Time
+								}
+							} `graphql:"reviews(first: 20)"`
 						} `graphql:"... on PullRequest"`
 					}
 				}
@@ -253,6 +285,7 @@ func getData(searchedOrg string, startDate string, endDate string) ([]string, in
 				progressbar.OptionShowBytes(false),
 				progressbar.OptionFullWidth(),
 				progressbar.OptionShowCount(),
+				progressbar.OptionSetWriter(os.Stderr),
 			)
 			//TODO: treat error
 			_ = bar.Add(1)
@@ -316,7 +349,8 @@ func getData(searchedOrg string, startDate string, endDate string) ([]string, in
 					// Is it an author that we don't want to track ?
 					authorToCheck := singlePr.Node.PullRequest.Author.Login
 					if !isExcludedAuthor(excludedGithubUsers, authorToCheck) {
-						author = authorToCheck
+						// The node ID is stable, even if the user changes their login
+						author = userAliases.canonicalWithNodeID(authorToCheck, singlePr.Node.PullRequest.Author.User.Id)
 					} else {
 						continue
 					}
@@ -337,9 +371,28 @@ func getData(searchedOrg string, startDate string, endDate string) ([]string, in
 				// clean and shorten the title
 				cleanedTitle := truncateString(cleanBody(singlePr.Node.PullRequest.Title), 30)
 
-				// data format: "org,repository,number,url,state,created_at,merged_at,user.login,month_year,title"
+				// The reactions of the other users (comments and reviews), used for the lifecycle metrics
+				var interactions []prInteraction
+				for _, comment := range singlePr.Node.PullRequest.Comments.Nodes {
+					interactions = append(interactions, prInteraction{
+						author:       comment.Author.Login,
+						resourcePath: comment.Author.ResourcePath,
+						at:           comment.CreatedAt,
+					})
+				}
+				for _, review := range singlePr.Node.PullRequest.Reviews.Nodes {
+					interactions = append(interactions, prInteraction{
+						author:       review.Author.Login,
+						resourcePath: review.Author.ResourcePath,
+						at:           review.SubmittedAt,
+						isApproval:   review.State == "APPROVED",
+					})
+				}
+				firstResponseAt, firstApprovalAt := computeFirstResponse(author, interactions)
+
+				// data format: "org,repository,number,url,state,created_at,merged_at,user.login,month_year,title,closed_at,first_response_at,first_approval_at"
 
-				dataLine := fmt.Sprintf("\"%s\",\"%s\",%d,\"%s\",\"%s\",\"%s\",\"%s\",\"%s\",\"%s\",\"%s\"",
+				dataLine := fmt.Sprintf("\"%s\",\"%s\",%d,\"%s\",\"%s\",\"%s\",\"%s\",\"%s\",\"%s\",\"%s\",\"%s\",\"%s\",\"%s\"",
 					singlePr.Node.PullRequest.Repository.Owner.Login,      // Org
 					singlePr.Node.PullRequest.Repository.Name,             //repository
 					singlePr.Node.PullRequest.Number,                      // PR number
@@ -350,6 +403,9 @@ func getData(searchedOrg string, startDate string, endDate string) ([]string, in
 					author,                                                // PR's author
 					singlePr.Node.PullRequest.CreatedAt.Format("2006-01"), // Creation month-year
 					cleanedTitle,                                          // PR's description
+					formatTimestamp(singlePr.Node.PullRequest.ClosedAt),   // Closed date&time
+					formatTimestamp(firstResponseAt),                      // First reaction of another user
+					formatTimestamp(firstApprovalAt),                      // First approval
 				)
 
 				if isRootDebug {
@@ -358,7 +414,7 @@ func getData(searchedOrg string, startDate string, endDate string) ([]string, in
 				prList = append(prList, dataLine)
 
 				if isVerbose {
-					fmt.Printf("%d-%d (%d/%d)  %s    %s\n", i, ii, (i*100)+ii, totalIssues, singlePr.Node.PullRequest.Author.Login, singlePr.Node.PullRequest.Url)
+					fmt.Fprintf(os.Stderr, "%d-%d (%d/%d)  %s    %s\n", i, ii, (i*100)+ii, totalIssues, singlePr.Node.PullRequest.Author.Login, singlePr.Node.PullRequest.Url)
 				}
 			}
 
@@ -379,10 +435,51 @@ func getData(searchedOrg string, startDate string, endDate string) ([]string, in
 		}
 	}
 	// as the progress exist doesn't do it
-	fmt.Printf("\n")
+	fmt.Fprintf(os.Stderr, "\n")
 	return prList, issueCount, nil
 }
 
+// A comment or a review made on a PR
+type prInteraction struct {
+	author       string
+	resourcePath string
+	at           time.Time
+	isApproval   bool
+}
+
+var appResourcePath_regexp = regexp.MustCompile(`^\/apps\/`)
+
+// Computes when a PR got its first response (comment or review) and its first approval.
+// Interactions of the PR author, of applications and of the excluded users (bots) are ignored.
+// A zero time is returned when there was no such interaction.
+// Only the interactions retrieved with the PR are available (the first 20 comments and reviews).
+func computeFirstResponse(prAuthor string, interactions []prInteraction) (time.Time, time.Time) {
+	var firstResponseAt, firstApprovalAt time.Time
+	for _, interaction := range interactions {
+		if interaction.at.IsZero() || interaction.author == "" || userAliases.isSameIdentity(interaction.author, prAuthor) {
+			continue
+		}
+		if appResourcePath_regexp.MatchString(interaction.resourcePath) || isExcludedAuthor(excludedGithubUsers, interaction.author) {
+			continue
+		}
+		if firstResponseAt.IsZero() || interaction.at.Before(firstResponseAt) {
+			firstResponseAt = interaction.at
+		}
+		if interaction.isApproval && (firstApprovalAt.IsZero() || interaction.at.Before(firstApprovalAt)) {
+			firstApprovalAt = interaction.at
+		}
+	}
+	return firstResponseAt, firstApprovalAt
+}
+
+// Formats a GitHub timestamp the way it is stored in the data files (empty if not set)
+func formatTimestamp(timestamp time.Time) string {
+	if timestamp.IsZero() {
+		return ""
+	}
+	return timestamp.Format(time.RFC3339)
+}
+
 // Makes a call to GitHub to get the total number of items. We can handle only 1K items in one
 // series of call. If above 1K we will have to split by decreasing the date range.
 func getTotalNumberOfItems(searchedOrg string, searchedMonth string) (int, error) {
@@ -25,6 +25,7 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -113,3 +114,32 @@ func Test_ExecuteGetSubmitterProcessExcludeIfPresent(t *testing.T) {
 	lines := strings.Split(actual.String(), "\n")
 	assert.Equal(t, expectedMsg, lines[0], "Function did not fail for the expected cause")
 }
+
+func Test_computeFirstResponse(t *testing.T) {
+	at := func(value string) time.Time {
+		parsed, _ := time.Parse(time.RFC3339, value)
+		return parsed
+	}
+	interactions := []prInteraction{
+		{author: "alice", at: at("2024-01-02T08:00:00Z")},
+		{author: "Alice", at: at("2024-01-02T09:00:00Z"), isApproval: true},
+		{author: "dependabot", resourcePath: "/apps/dependabot", at: at("2024-01-02T10:00:00Z")},
+		{author: "bob", at: at("2024-01-04T10:00:00Z"), isApproval: true},
+		{author: "carol", at: at("2024-01-03T10:00:00Z")},
+		{author: "dave", at: at("2024-01-05T10:00:00Z"), isApproval: true},
+		{author: "", at: at("2024-01-01T10:00:00Z")},
+	}
+
+	firstResponse, firstApproval := computeFirstResponse("alice", interactions)
+	assert.Equal(t, at("2024-01-03T10:00:00Z"), firstResponse, "The author, applications and ghosts should be ignored")
+	assert.Equal(t, at("2024-01-04T10:00:00Z"), firstApproval)
+
+	firstResponse, firstApproval = computeFirstResponse("alice", interactions[:3])
+	assert.True(t, firstResponse.IsZero(), "No response expected")
+	assert.True(t, firstApproval.IsZero(), "No approval expected")
+}
+
+func Test_formatTimestamp(t *testing.T) {
+	assert.Equal(t, "", formatTimestamp(time.Time{}))
+	assert.Equal(t, "2024-01-03T10:00:00Z", formatTimestamp(time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC)))
+}
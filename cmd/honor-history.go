@@ -0,0 +1,256 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var honorHistoryOutputFileName string
+var honorHistoryFormat string
+
+// The name of the history of the honored contributors, stored in the data directory
+const defaultHonorHistoryFileName = "honor_history.csv"
+
+// The number of months during which an honored contributor can't be picked again (see "--cooldown")
+const defaultHonorCooldownMonths = 12
+
+// honorHistoryCmd represents the "honor history" command
+var honorHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Lists the contributors honored in the past",
+	Long: `Lists the contributors picked by the "honor" command, as recorded in the history
+file ("honor_history.csv" in the data directory). Each selection is appended to this
+file, which must not be edited.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !isValidDir(honorDataDir) {
+			return fmt.Errorf("Supplied DataDir \"%s\" does not exist.", honorDataDir)
+		}
+		if err := validateOutputFormat(honorHistoryFormat, []string{formatCSV, formatMarkdown, formatJSON}); err != nil {
+			return err
+		}
+		return performHonorHistoryListing(filepath.Join(honorDataDir, defaultHonorHistoryFileName), honorHistoryOutputFileName, honorHistoryFormat)
+	},
+}
+
+func init() {
+	honorCmd.AddCommand(honorHistoryCmd)
+
+	honorHistoryCmd.Flags().StringVarP(&honorHistoryOutputFileName, "out", "o", stdStreamName, "Output file name (\"-\" to write to the standard output)")
+	honorHistoryCmd.Flags().StringVarP(&honorHistoryFormat, "format", "f", formatCSV, "Output format (csv, md or json)")
+}
+
+// A past selection of the "honor" command
+type honorHistoryEntry struct {
+	runDate      string
+	month        string
+	handle       string
+	fullName     string
+	nbrOfPRs     string
+	repositories string
+	seed         string
+	rank         string
+	reason       string
+}
+
+// Main function of the "honor history" command
+func performHonorHistoryListing(historyFileName string, outputFileName string, format string) error {
+	history, err := loadHonorHistory(historyFileName)
+	if err != nil {
+		return err
+	}
+	if len(history) == 0 && isVerbose {
+		fmt.Fprintf(os.Stderr, "No contributor honored yet (\"%s\" not found or empty)\n", historyFileName)
+	}
+
+	header := []string{"run_date", "month", "rank", "user", "name", "PRs", "repositories", "seed", "reason"}
+	var rows [][]string
+	for _, entry := range history {
+		rows = append(rows, []string{entry.runDate, entry.month, entry.rank, entry.handle, entry.fullName, entry.nbrOfPRs, entry.repositories, entry.seed, entry.reason})
+	}
+	return writeTableOutput(outputFileName, format, header, []string{"rank", "PRs", "seed"}, rows)
+}
+
+// Loads the history of the honored contributors, sorted by month (and run date).
+// A missing history file is not an error: nobody has been honored yet.
+func loadHonorHistory(historyFileName string) ([]honorHistoryEntry, error) {
+	if !fileExist(historyFileName) {
+		return nil, nil
+	}
+
+	table, err := loadCSVtable(historyFileName)
+	if err != nil {
+		return nil, err
+	}
+	if table.schema.kind != schemaHonor {
+		return nil, fmt.Errorf("\"%s\" is not an honor history file (\"%s\" file instead of \"%s\").", historyFileName, table.schema.kind, schemaHonor)
+	}
+
+	var history []honorHistoryEntry
+	for _, record := range table.records {
+		history = append(history, honorHistoryEntry{
+			runDate:      table.get(record, "RUN_DATE"),
+			month:        table.get(record, "MONTH"),
+			handle:       table.get(record, "GH_HANDLE"),
+			fullName:     table.get(record, "FULL_NAME"),
+			nbrOfPRs:     table.get(record, "NBR_PR"),
+			repositories: table.get(record, "REPOSITORIES"),
+			seed:         table.get(record, "SEED"),
+			rank:         table.get(record, "RANK"),
+			reason:       table.get(record, "REASON"),
+		})
+	}
+
+	sort.SliceStable(history, func(i, j int) bool {
+		if history[i].month != history[j].month {
+			return history[i].month < history[j].month
+		}
+		return history[i].runDate < history[j].runDate
+	})
+	return history, nil
+}
+
+// Returns the contributors honored during the cool-down period preceding the month.
+// The month itself is not part of it so that a selection can be run again.
+func computeRecentlyHonored(history []honorHistoryEntry, month string, cooldownMonths int) []string {
+	if cooldownMonths <= 0 {
+		return nil
+	}
+	firstMonth := addMonths(month, -cooldownMonths)
+
+	var recentlyHonored []string
+	for _, entry := range history {
+		if entry.month >= firstMonth && entry.month < month && !isExcludedAuthor(recentlyHonored, entry.handle) {
+			recentlyHonored = append(recentlyHonored, entry.handle)
+		}
+	}
+	return recentlyHonored
+}
+
+// Appends a selection (as generated for the honor output file) to the history, creating it if needed.
+// A history started with an older version of the tool is first upgraded to the current columns.
+// The rows already recorded for the same month and contributor (a selection run again) are replaced.
+func appendToHonorHistory(historyFileName string, honorCSVlines []string) error {
+	isNewFile := !fileExist(historyFileName)
+	if !isNewFile {
+		if err := migrateHonorHistory(historyFileName); err != nil {
+			return err
+		}
+		isReplaced, err := replaceInHonorHistory(historyFileName, honorCSVlines)
+		if err != nil || isReplaced {
+			return err
+		}
+	}
+
+	out, err := os.OpenFile(historyFileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("Unable to update the honor history \"%s\": %v", historyFileName, err)
+	}
+	defer out.Close()
+
+	var lines []string
+	if isNewFile {
+		lines = append(lines, generateHonoredContributorDataCSVheader())
+	}
+	lines = append(lines, honorCSVlines...)
+	writeCSVtoFile(out, true, true, "", lines)
+	return nil
+}
+
+// Rewrites the history with the selection if it already has rows for the same month and contributor,
+// dropping these rows. Returns false, leaving the file untouched, if there is nothing to replace.
+func replaceInHonorHistory(historyFileName string, honorCSVlines []string) (bool, error) {
+	selection, err := readCSVtable(strings.NewReader(generateHonoredContributorDataCSVheader() + "\n" + strings.Join(honorCSVlines, "\n")))
+	if err != nil {
+		return false, fmt.Errorf("Invalid honor selection: %v", err)
+	}
+	selectedKeys := make(map[string]bool)
+	for _, record := range selection.records {
+		selectedKeys[honorHistoryKey(selection.get(record, "MONTH"), selection.get(record, "GH_HANDLE"))] = true
+	}
+
+	table, err := loadCSVtable(historyFileName)
+	if err != nil {
+		return false, err
+	}
+	var lines []string
+	isReplaced := false
+	for _, record := range table.records {
+		if selectedKeys[honorHistoryKey(table.get(record, "MONTH"), table.get(record, "GH_HANDLE"))] {
+			isReplaced = true
+			continue
+		}
+		lines = append(lines, formatHonorCSVrecord(record))
+	}
+	if !isReplaced {
+		return false, nil
+	}
+
+	if isVerbose {
+		fmt.Fprintf(os.Stderr, "Replacing the selection already recorded in the honor history \"%s\"\n", historyFileName)
+	}
+	lines = append(lines, honorCSVlines...)
+	return true, writeOutputFile(historyFileName, false, currentSchema(schemaHonor).headerLine(), lines)
+}
+
+// Identifies a history row by its month and contributor (GitHub handles are case insensitive)
+func honorHistoryKey(month string, handle string) string {
+	return month + "/" + strings.ToLower(handle)
+}
+
+// Rewrites the history file with the current honor columns if its header is an older one,
+// so that the appended records match the header.
+func migrateHonorHistory(historyFileName string) error {
+	table, err := loadCSVtable(historyFileName)
+	if err != nil {
+		return err
+	}
+	if table.schema.kind != schemaHonor {
+		return fmt.Errorf("\"%s\" is not an honor history file (found %s data)", historyFileName, table.schema.kind)
+	}
+	targetSchema, migratedRecords := migrateTable(table)
+	if validateHeader(trimmedHeader(table.header), targetSchema.columns, false) {
+		return nil
+	}
+
+	if isVerbose {
+		fmt.Fprintf(os.Stderr, "Upgrading the honor history \"%s\" from version %d to %d\n", historyFileName, table.schema.version, targetSchema.version)
+	}
+	var lines []string
+	for _, record := range migratedRecords {
+		lines = append(lines, formatHonorCSVrecord(record))
+	}
+	out, err := os.Create(historyFileName)
+	if err != nil {
+		return fmt.Errorf("Unable to upgrade the honor history \"%s\": %v", historyFileName, err)
+	}
+	defer out.Close()
+	writeCSVtoFile(out, false, false, targetSchema.headerLine(), lines)
+	return nil
+}
@@ -0,0 +1,198 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_loadHonorHistory(t *testing.T) {
+	history, err := loadHonorHistory("../test-data/monthly/honor_history.csv")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(history))
+	assert.Equal(t, honorHistoryEntry{runDate: "2023-12-02T10-00-00Z", month: "2023-11", handle: "alice-old", fullName: "Alice",
+		nbrOfPRs: "1", repositories: "jenkins-infra/helpdesk", seed: "7"}, history[0])
+	assert.Equal(t, "carol", history[2].handle)
+
+	// nobody honored yet
+	history, err = loadHonorHistory(filepath.Join(t.TempDir(), "inexistent.csv"))
+	assert.NoError(t, err)
+	assert.Empty(t, history)
+
+	_, err = loadHonorHistory("../test-data/monthly/user_profiles.csv")
+	assert.Error(t, err, "Not an honor file")
+}
+
+func Test_computeRecentlyHonored(t *testing.T) {
+	history, err := loadHonorHistory("../test-data/monthly/honor_history.csv")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		month    string
+		cooldown int
+		want     []string
+	}{
+		{"default cool-down", "2024-03", 12, []string{"alice-old", "bob", "carol"}},
+		{"short cool-down", "2024-03", 1, []string{"carol"}},
+		{"month itself is not part of the cool-down", "2024-02", 12, []string{"alice-old", "bob"}},
+		{"picks of later months are ignored", "2023-12", 12, []string{"alice-old"}},
+		{"no cool-down", "2024-03", 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, computeRecentlyHonored(history, tt.month, tt.cooldown))
+		})
+	}
+}
+
+func Test_appendToHonorHistory(t *testing.T) {
+	historyFileName := filepath.Join(t.TempDir(), defaultHonorHistoryFileName)
+	first := `"2024-05-02T10-00-00Z", "2024-04", "basil", "Basil", "", "", "", "69", "jenkinsci/jenkins", "1", "1", "random draw among 185 contributors"`
+	second := `"2024-06-02T10-00-00Z", "2024-05", "gounthar", "Bruno", "", "", "", "40", "jenkinsci/jenkins", "2", "1", "random draw among 170 contributors"`
+	third := `"2024-06-02T10-00-00Z", "2024-05", "smerle33", "Stéphane", "", "", "", "31", "jenkins-infra/helpdesk", "2", "2", "random draw among 170 contributors"`
+
+	assert.NoError(t, appendToHonorHistory(historyFileName, []string{first}))
+	assert.NoError(t, appendToHonorHistory(historyFileName, []string{second, third}))
+
+	content, err := os.ReadFile(historyFileName)
+	assert.NoError(t, err)
+	assert.Equal(t, generateHonoredContributorDataCSVheader()+"\n"+first+"\n"+second+"\n"+third+"\n", string(content))
+
+	history, err := loadHonorHistory(historyFileName)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"basil", "gounthar", "smerle33"}, []string{history[0].handle, history[1].handle, history[2].handle})
+	assert.Equal(t, []string{"1", "1", "2"}, []string{history[0].rank, history[1].rank, history[2].rank})
+	assert.Equal(t, "random draw among 170 contributors", history[2].reason)
+}
+
+func Test_appendToHonorHistory_quotedFields(t *testing.T) {
+	historyFileName := filepath.Join(t.TempDir(), defaultHonorHistoryFileName)
+	contributor := HonoredContributorData{month: "2024-04", handle: "jdoe", fullName: `John "JD" Doe`, authorCompany: `"Acme", Inc.`,
+		totalPRs_found: "3", repositories: "jenkinsci/jenkins", seed: "42", rank: "1", reason: "random draw among 12 contributors"}
+
+	assert.NoError(t, appendToHonorHistory(historyFileName, []string{formatHonoredContributorCSVline(contributor, "2024-05-02T10-00-00Z")}))
+	contributor.month = "2024-05"
+	assert.NoError(t, appendToHonorHistory(historyFileName, []string{formatHonoredContributorCSVline(contributor, "2024-06-02T10-00-00Z")}))
+	// Running the last month again rewrites the whole history
+	assert.NoError(t, appendToHonorHistory(historyFileName, []string{formatHonoredContributorCSVline(contributor, "2024-06-03T10-00-00Z")}))
+
+	history, err := loadHonorHistory(historyFileName)
+	assert.NoError(t, err, "The history should still be readable")
+	assert.Equal(t, 2, len(history))
+	assert.Equal(t, `John "JD" Doe`, history[0].fullName)
+	assert.Equal(t, "jdoe", history[1].handle)
+	assert.Equal(t, "2024-06-03T10-00-00Z", history[1].runDate)
+}
+
+func Test_appendToHonorHistory_sameMonthAgain(t *testing.T) {
+	historyFileName := filepath.Join(t.TempDir(), defaultHonorHistoryFileName)
+	first := `"2024-05-02T10-00-00Z", "2024-04", "basil", "Basil", "", "", "", "69", "jenkinsci/jenkins", "1", "1", "random draw among 185 contributors"`
+	second := `"2024-06-02T10-00-00Z", "2024-05", "gounthar", "Bruno", "", "", "", "40", "jenkinsci/jenkins", "2", "1", "random draw among 170 contributors"`
+	third := `"2024-06-02T10-00-00Z", "2024-05", "smerle33", "Stéphane", "", "", "", "31", "jenkins-infra/helpdesk", "2", "2", "random draw among 170 contributors"`
+	rerun := `"2024-06-03T10-00-00Z", "2024-05", "GounThar", "Bruno", "", "", "", "41", "jenkinsci/jenkins", "2", "1", "random draw among 171 contributors"`
+
+	assert.NoError(t, appendToHonorHistory(historyFileName, []string{first, second, third}))
+	assert.NoError(t, appendToHonorHistory(historyFileName, []string{rerun}))
+
+	content, err := os.ReadFile(historyFileName)
+	assert.NoError(t, err)
+	assert.Equal(t, generateHonoredContributorDataCSVheader()+"\n"+first+"\n"+third+"\n"+rerun+"\n", string(content))
+
+	history, err := loadHonorHistory(historyFileName)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(history))
+	assert.Equal(t, "GounThar", history[2].handle)
+	assert.Equal(t, "41", history[2].nbrOfPRs)
+}
+
+func Test_appendToHonorHistory_olderHistory(t *testing.T) {
+	historyFileName, err := duplicateFile("../test-data/monthly/honor_history.csv", t.TempDir(), false)
+	assert.NoError(t, err, "Unexpected data file duplication error")
+	line := `"2024-04-02T10-00-00Z", "2024-03", "dave", "Dave", "", "", "", "1", "jenkinsci/jenkins", "5", "1", "random draw among 3 contributors"`
+
+	assert.NoError(t, appendToHonorHistory(historyFileName, []string{line}))
+
+	content, err := os.ReadFile(historyFileName)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(content), generateHonoredContributorDataCSVheader()+"\n"), "The header should have been upgraded")
+	history, err := loadHonorHistory(historyFileName)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(history))
+	assert.Equal(t, "bob", history[1].handle)
+	assert.Equal(t, "42", history[1].seed)
+	assert.Equal(t, "", history[1].rank)
+	assert.Equal(t, "dave", history[3].handle)
+	assert.Equal(t, "1", history[3].rank)
+	assert.Equal(t, "random draw among 3 contributors", history[3].reason)
+}
+
+func Test_appendToHonorHistory_notAHistory(t *testing.T) {
+	historyFileName, err := duplicateFile("../test-data/monthly/user_profiles.csv", t.TempDir(), false)
+	assert.NoError(t, err, "Unexpected data file duplication error")
+
+	assert.ErrorContains(t, appendToHonorHistory(historyFileName, []string{`"2024-04-02T10-00-00Z", "2024-03"`}), "not an honor history file")
+}
+
+func Test_honorHistoryCommand_integrationTest(t *testing.T) {
+	outputFileName := filepath.Join(t.TempDir(), "history.md")
+	defer func() {
+		honorHistoryFormat = formatCSV
+		honorHistoryOutputFileName = stdStreamName
+		honorDataDir = "data"
+	}()
+
+	actual := new(bytes.Buffer)
+	rootCmd.SetOut(actual)
+	rootCmd.SetErr(actual)
+	rootCmd.SetArgs([]string{"honor", "history", "--data_dir=../test-data/monthly", "--format=md", "--out=" + outputFileName})
+
+	error := rootCmd.Execute()
+
+	assert.NoError(t, error, "Call should not have failed")
+	content, err := os.ReadFile(outputFileName)
+	assert.NoError(t, err, "History should have been listed")
+	assert.Equal(t, "| run_date | month | rank | user | name | PRs | repositories | seed | reason |\n"+
+		"|---|---|---|---|---|---|---|---|---|\n"+
+		"| 2023-12-02T10-00-00Z | 2023-11 |  | alice-old | Alice | 1 | jenkins-infra/helpdesk | 7 |  |\n"+
+		"| 2024-02-03T10-00-00Z | 2024-01 |  | bob | Bob | 2 | jenkinsci/jenkins | 42 |  |\n"+
+		"| 2024-03-05T10-00-00Z | 2024-02 |  | carol | Carol | 3 | jenkinsci/jenkins jenkins-docs/docs | 12 |  |\n", string(content))
+}
+
+func Test_honorHistoryCommand_invalidDataDir(t *testing.T) {
+	defer func() { honorDataDir = "data" }()
+
+	actual := new(bytes.Buffer)
+	rootCmd.SetOut(actual)
+	rootCmd.SetErr(actual)
+	rootCmd.SetArgs([]string{"honor", "history", "--data_dir=inexistentDir"})
+
+	error := rootCmd.Execute()
+
+	assert.Error(t, error, "Call should have failed")
+}
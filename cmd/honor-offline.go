@@ -0,0 +1,89 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Returns the cached profile of the user (whatever its age). Without a cached profile, a minimal
+// one is built from the login: the user is then considered as a person unless the login is the one of a bot.
+func lookupOfflineUserProfile(login string, cache map[string]userProfile) userProfile {
+	if profile, exists := cache[strings.ToLower(login)]; exists {
+		return profile
+	}
+
+	profile := userProfile{
+		login:       login,
+		url:         "https://github.com/" + login,
+		avatarURL:   "https://github.com/" + login + ".png",
+		accountType: accountUser,
+	}
+	if botLogin_regexp.MatchString(login) {
+		profile.accountType = accountBot
+	}
+	return profile
+}
+
+// Gets the PRs of the submitter from the already extracted submissions (the offline equivalent of getSubmittersPRfromGH)
+func getSubmittersPRfromSubmissions(submittersName string, profile userProfile, submittersPRs string, monthToSelectFrom string,
+	orgs []string, submissions []submissionRecord) (error, HonoredContributorData) {
+
+	contributorData := newHonoredContributorData(submittersName, profile, submittersPRs, monthToSelectFrom)
+
+	for _, submission := range submissions {
+		if submission.month != monthToSelectFrom || !userAliases.isSameIdentity(submission.user, submittersName) {
+			continue
+		}
+		if !isOneOfOrgs(submission.org, orgs) {
+			continue
+		}
+		contributorData.pullRequests = append(contributorData.pullRequests, honoredPullRequest{
+			url:        submission.url,
+			title:      submission.title,
+			repository: submission.repositorySpec(),
+		})
+	}
+
+	contributorData.totalPRs_found = strconv.Itoa(len(contributorData.pullRequests))
+	contributorData.setRepositories()
+
+	if isVerbose {
+		fmt.Fprint(os.Stderr, "\n\n")
+		fmt.Fprintln(os.Stderr, prettyPrint_HonoredContributorData(contributorData))
+	}
+
+	return nil, contributorData
+}
+
+// Returns true if the organization is one of the list (GitHub organization names are case insensitive)
+func isOneOfOrgs(org string, orgs []string) bool {
+	for _, candidate := range orgs {
+		if strings.EqualFold(org, candidate) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,211 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_lookupOfflineUserProfile(t *testing.T) {
+	cache, err := loadProfileCache("../test-data/monthly/user_profiles.csv")
+	assert.NoError(t, err)
+
+	profile := lookupOfflineUserProfile("Bob", cache)
+	assert.Equal(t, "Bob Smith", profile.name)
+	assert.True(t, isHonorableAccount(profile))
+
+	profile = lookupOfflineUserProfile("erin", cache)
+	assert.Equal(t, userProfile{login: "erin", url: "https://github.com/erin", avatarURL: "https://github.com/erin.png", accountType: accountUser}, profile)
+	assert.True(t, isHonorableAccount(profile))
+
+	profile = lookupOfflineUserProfile("dependabot[bot]", cache)
+	assert.False(t, isHonorableAccount(profile))
+}
+
+func Test_getSubmittersPRfromSubmissions(t *testing.T) {
+	submissions, err := loadSubmissions("../test-data/monthly/submissions-2024-03.csv")
+	assert.NoError(t, err)
+	profile := userProfile{login: "bob", name: "Bob Smith", url: "https://github.com/bob", avatarURL: "https://avatars.githubusercontent.com/u/1002?v=4", company: "CloudBees, Inc."}
+
+	err, got := getSubmittersPRfromSubmissions("bob", profile, "2", "2024-03", defaultHonorOrgs, submissions)
+	assert.NoError(t, err)
+	assert.Equal(t, "Bob Smith", got.fullName)
+	assert.Equal(t, "CloudBees, Inc.", got.authorCompany)
+	assert.Equal(t, "2", got.totalPRs_expected)
+	assert.Equal(t, "2", got.totalPRs_found)
+	assert.Equal(t, "jenkinsci/jenkins", got.repositories)
+	assert.Equal(t, []honoredRepository{{name: "jenkinsci/jenkins", prs: 2}}, got.repositoryPRs)
+	assert.Equal(t, "https://github.com/jenkinsci/jenkins/pull/9020", got.pullRequests[0].url)
+
+	// the PRs of other organizations are not counted (as with the GitHub search)
+	err, got = getSubmittersPRfromSubmissions("bob", profile, "2", "2024-03", []string{"jenkins-infra"}, submissions)
+	assert.NoError(t, err)
+	assert.Equal(t, "0", got.totalPRs_found)
+	assert.Empty(t, got.repositoryPRs)
+}
+
+func Test_getSubmittersPRfromSubmissions_withAliases(t *testing.T) {
+	var err error
+	userAliases, err = loadIdentityAliases("../test-data/aliases.txt")
+	assert.NoError(t, err)
+	defer func() { userAliases = identityAliases{} }()
+
+	submissions, err := loadSubmissions("../test-data/monthly/submissions-2024-03.csv")
+	assert.NoError(t, err)
+
+	err, got := getSubmittersPRfromSubmissions("dave", userProfile{login: "dave"}, "2", "2024-03", defaultHonorOrgs, submissions)
+	assert.NoError(t, err)
+	assert.Equal(t, "2", got.totalPRs_found, "erin is dave's former account")
+	assert.Equal(t, "jenkinsci/ldap-plugin jenkinsci/jenkins", got.repositories)
+}
+
+func Test_isOneOfOrgs(t *testing.T) {
+	assert.True(t, isOneOfOrgs("JenkinsCI", defaultHonorOrgs))
+	assert.False(t, isOneOfOrgs("jenkins", defaultHonorOrgs))
+	assert.False(t, isOneOfOrgs("jenkinsci", nil))
+}
+
+// Prepares a data directory with the files needed to honor a contributor of 2024-03 offline
+func prepareOfflineHonorDataDir(t *testing.T) string {
+	dataDir := t.TempDir()
+	for _, fileName := range []string{"pr_per_submitter-2024-03.csv", "submissions-2024-01.csv", "submissions-2024-02.csv",
+		"submissions-2024-03.csv", "user_profiles.csv"} {
+		_, err := duplicateFile(filepath.Join("../test-data/monthly", fileName), dataDir, false)
+		assert.NoError(t, err)
+	}
+	return dataDir
+}
+
+func Test_performHonorContributorSelection_offline(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	dataDir := prepareOfflineHonorDataDir(t)
+	outputFileName := filepath.Join(dataDir, "honored.json")
+
+	parameters := newTestHonorParameters(42)
+	parameters.isOffline = true
+	parameters.count = 3
+	parameters.outputFormat = formatJSON
+	assert.NoError(t, performHonorContributorSelection(dataDir, outputFileName, "2024-03", parameters))
+
+	content, err := os.ReadFile(outputFileName)
+	assert.NoError(t, err)
+	var honored []map[string]interface{}
+	assert.NoError(t, json.Unmarshal(content, &honored))
+	assert.Equal(t, 3, len(honored))
+	handles := make(map[string]map[string]interface{})
+	for _, contributor := range honored {
+		handles[contributor["handle"].(string)] = contributor
+	}
+	assert.Equal(t, float64(2), handles["bob"]["pull_request_count"])
+	assert.Equal(t, "Bob Smith", handles["bob"]["name"])
+	assert.Equal(t, "https://github.com/erin", handles["erin"]["url"])
+	assert.Equal(t, float64(1), handles["dave"]["pull_request_count"])
+
+	history, err := loadHonorHistory(filepath.Join(dataDir, defaultHonorHistoryFileName))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(history))
+
+	// a new run for the same month gives the same selection
+	assert.NoError(t, performHonorContributorSelection(dataDir, outputFileName, "2024-03", parameters))
+	again, err := os.ReadFile(outputFileName)
+	assert.NoError(t, err)
+	var honoredAgain []map[string]interface{}
+	assert.NoError(t, json.Unmarshal(again, &honoredAgain))
+	for i := range honored {
+		assert.Equal(t, honored[i]["handle"], honoredAgain[i]["handle"])
+	}
+	history, err = loadHonorHistory(filepath.Join(dataDir, defaultHonorHistoryFileName))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(history), "The selection should not be recorded twice")
+}
+
+func Test_performHonorContributorSelection_offlineWithoutSubmissions(t *testing.T) {
+	dataDir := t.TempDir()
+	_, err := duplicateFile("../test-data/monthly/pr_per_submitter-2024-03.csv", dataDir, false)
+	assert.NoError(t, err)
+
+	parameters := newTestHonorParameters(42)
+	parameters.isOffline = true
+	assert.ErrorContains(t, performHonorContributorSelection(dataDir, "", "2024-03", parameters), "offline mode needs the submissions")
+}
+
+func Test_honorCommand_offline(t *testing.T) {
+	dataDir := prepareOfflineHonorDataDir(t)
+	outputFileName := filepath.Join(dataDir, "honored.md")
+	defer func() { honorIsOffline = false; honorFormat = formatCSV; honorOutput = ""; honorDataDir = "data" }()
+
+	actual := new(bytes.Buffer)
+	rootCmd.SetOut(actual)
+	rootCmd.SetErr(actual)
+	rootCmd.SetArgs([]string{"honor", "2024-03", "--offline", "--data_dir=" + dataDir, "--format=md", "--output=" + outputFileName})
+
+	error := rootCmd.Execute()
+
+	assert.NoError(t, error, "Call should not have failed")
+	content, err := os.ReadFile(outputFileName)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "### Contributor of the month: ")
+}
+
+func Test_performHonorContributorSelection_reasonCountsEligibleContributors(t *testing.T) {
+	dataDir := prepareOfflineHonorDataDir(t)
+	_, err := duplicateFile("../test-data/monthly/honor_history.csv", dataDir, false)
+	assert.NoError(t, err)
+	outputFileName := filepath.Join(dataDir, "honored.json")
+
+	// bob was honored in 2024-01 and erin is excluded: dave is the only eligible contributor
+	parameters := newTestHonorParameters(42)
+	parameters.isOffline = true
+	parameters.outputFormat = formatJSON
+	parameters.excludedUsers = []string{"erin"}
+	assert.NoError(t, performHonorContributorSelection(dataDir, outputFileName, "2024-03", parameters))
+
+	content, err := os.ReadFile(outputFileName)
+	assert.NoError(t, err)
+	var honored []map[string]interface{}
+	assert.NoError(t, json.Unmarshal(content, &honored))
+	assert.Equal(t, 1, len(honored))
+	assert.Equal(t, "dave", honored[0]["handle"])
+	assert.Equal(t, "random draw among 1 contributors", honored[0]["reason"])
+
+	history, err := loadHonorHistory(filepath.Join(dataDir, defaultHonorHistoryFileName))
+	assert.NoError(t, err)
+	assert.Equal(t, "random draw among 1 contributors", history[len(history)-1].reason)
+}
+
+func Test_performHonorContributorSelection_unwritableAnnouncement(t *testing.T) {
+	dataDir := prepareOfflineHonorDataDir(t)
+
+	parameters := newTestHonorParameters(42)
+	parameters.isOffline = true
+	parameters.announcementFileName = filepath.Join(dataDir, "missing_dir", "announcement.txt")
+	err := performHonorContributorSelection(dataDir, filepath.Join(dataDir, "honored.csv"), "2024-03", parameters)
+
+	assert.ErrorContains(t, err, "Unable to create")
+	assert.NoFileExists(t, filepath.Join(dataDir, defaultHonorHistoryFileName), "The month should not be recorded as honored")
+}
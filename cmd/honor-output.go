@@ -0,0 +1,246 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The honored contributor as written in the JSON output (for the contributors.jenkins.io site)
+type honoredContributorJSON struct {
+	RunDate      string                   `json:"run_date"`
+	Month        string                   `json:"month"`
+	Handle       string                   `json:"handle"`
+	Name         string                   `json:"name"`
+	Company      string                   `json:"company"`
+	URL          string                   `json:"url"`
+	AvatarURL    string                   `json:"avatar_url"`
+	NbrOfPRs     int                      `json:"pull_request_count"`
+	Repositories []honoredRepositoryJSON  `json:"repositories"`
+	PullRequests []honoredPullRequestJSON `json:"pull_requests"`
+	Seed         string                   `json:"seed"`
+	Rank         int                      `json:"rank"`
+	Reason       string                   `json:"reason"`
+}
+
+type honoredRepositoryJSON struct {
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	NbrOfPRs int    `json:"pull_request_count"`
+}
+
+type honoredPullRequestJSON struct {
+	Title      string `json:"title"`
+	URL        string `json:"url"`
+	Repository string `json:"repository"`
+}
+
+// Formats the honored contributors in the requested format (csv, json, md or html)
+func formatHonoredContributors(honoredContributors []HonoredContributorData, runDate string, format string) (string, error) {
+	var strBuffer strings.Builder
+	switch format {
+	case formatCSV:
+		strBuffer.WriteString(generateHonoredContributorDataCSVheader() + "\n")
+		for _, contributorData := range honoredContributors {
+			strBuffer.WriteString(formatHonoredContributorCSVline(contributorData, runDate) + "\n")
+		}
+	case formatJSON:
+		return formatHonoredContributorsAsJSON(honoredContributors, runDate)
+	case formatMarkdown:
+		for i, contributorData := range honoredContributors {
+			if i > 0 {
+				strBuffer.WriteString("\n")
+			}
+			strBuffer.WriteString(formatHonoredContributorAsMarkdown(contributorData))
+		}
+	case formatHTML:
+		for _, contributorData := range honoredContributors {
+			strBuffer.WriteString(formatHonoredContributorAsHTML(contributorData))
+		}
+	default:
+		return "", fmt.Errorf("Unsupported output format \"%s\"", format)
+	}
+	return strBuffer.String(), nil
+}
+
+// Returns the CSV data line of the honored contributor (as written in the output and history files)
+func formatHonoredContributorCSVline(contributorData HonoredContributorData, runDate string) string {
+	return quoteCSVfield(runDate) + ", " + generateHonoredContributorDataAsCSV(contributorData)
+}
+
+// Formats the honored contributors as a JSON array of objects
+func formatHonoredContributorsAsJSON(honoredContributors []HonoredContributorData, runDate string) (string, error) {
+	output := []honoredContributorJSON{}
+	for _, contributorData := range honoredContributors {
+		output = append(output, newHonoredContributorJSON(contributorData, runDate))
+	}
+
+	jsonOutput, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(jsonOutput) + "\n", nil
+}
+
+// Converts the honored contributor to its JSON representation
+func newHonoredContributorJSON(contributorData HonoredContributorData, runDate string) honoredContributorJSON {
+	nbrOfPRs, _ := strconv.Atoi(contributorData.totalPRs_found)
+	rank, _ := strconv.Atoi(contributorData.rank)
+	output := honoredContributorJSON{
+		RunDate:      runDate,
+		Month:        contributorData.month,
+		Handle:       contributorData.handle,
+		Name:         contributorData.fullName,
+		Company:      contributorData.authorCompany,
+		URL:          contributorData.authorURL,
+		AvatarURL:    contributorData.authorAvatarUrl,
+		NbrOfPRs:     nbrOfPRs,
+		Repositories: []honoredRepositoryJSON{},
+		PullRequests: []honoredPullRequestJSON{},
+		Seed:         contributorData.seed,
+		Rank:         rank,
+		Reason:       contributorData.reason,
+	}
+	for _, repository := range contributorData.repositoryPRs {
+		output.Repositories = append(output.Repositories, honoredRepositoryJSON{Name: repository.name, URL: repositoryURL(repository.name), NbrOfPRs: repository.prs})
+	}
+	for _, pr := range contributorData.pullRequests {
+		output.PullRequests = append(output.PullRequests, honoredPullRequestJSON{Title: pr.title, URL: pr.url, Repository: pr.repository})
+	}
+	return output
+}
+
+// Formats the honored contributor as a Markdown snippet
+func formatHonoredContributorAsMarkdown(contributorData HonoredContributorData) string {
+	var strBuffer strings.Builder
+	strBuffer.WriteString(fmt.Sprintf("### Contributor of the month: %s\n\n", escapeMarkdown(displayName(contributorData))))
+	strBuffer.WriteString(fmt.Sprintf("<img src=\"%s\" alt=\"%s\" width=\"100\"/>\n\n",
+		html.EscapeString(contributorData.authorAvatarUrl), html.EscapeString(contributorData.handle)))
+	strBuffer.WriteString(fmt.Sprintf("[@%s](%s)", escapeMarkdown(contributorData.handle), escapeMarkdownURL(contributorData.authorURL)))
+	if contributorData.authorCompany != "" {
+		strBuffer.WriteString(fmt.Sprintf(" (%s)", escapeMarkdown(contributorData.authorCompany)))
+	}
+	strBuffer.WriteString(fmt.Sprintf(" submitted %s pull request(s) in %s",
+		escapeMarkdown(contributorData.totalPRs_found), escapeMarkdown(displayMonth(contributorData.month))))
+
+	if len(contributorData.repositoryPRs) > 0 {
+		var links []string
+		for _, repository := range contributorData.repositoryPRs {
+			links = append(links, fmt.Sprintf("[%s](%s) (%d)", escapeMarkdown(repository.name), escapeMarkdownURL(repositoryURL(repository.name)), repository.prs))
+		}
+		strBuffer.WriteString(" to " + strings.Join(links, ", "))
+	}
+	strBuffer.WriteString(".\n")
+
+	if len(contributorData.pullRequests) > 0 {
+		strBuffer.WriteString("\n")
+		for _, pr := range contributorData.pullRequests {
+			strBuffer.WriteString(fmt.Sprintf("- [%s](%s) (%s)\n", escapeMarkdown(pr.title), escapeMarkdownURL(pr.url), escapeMarkdown(pr.repository)))
+		}
+	}
+	return strBuffer.String()
+}
+
+// Formats the honored contributor as an HTML snippet
+func formatHonoredContributorAsHTML(contributorData HonoredContributorData) string {
+	var strBuffer strings.Builder
+	strBuffer.WriteString("<div class=\"honored-contributor\">\n")
+	strBuffer.WriteString(fmt.Sprintf("  <h3>Contributor of the month: %s</h3>\n", html.EscapeString(displayName(contributorData))))
+	strBuffer.WriteString(fmt.Sprintf("  <a href=\"%s\"><img src=\"%s\" alt=\"%s\" width=\"100\"/></a>\n",
+		html.EscapeString(contributorData.authorURL), html.EscapeString(contributorData.authorAvatarUrl), html.EscapeString(contributorData.handle)))
+	strBuffer.WriteString(fmt.Sprintf("  <p><a href=\"%s\">@%s</a>", html.EscapeString(contributorData.authorURL), html.EscapeString(contributorData.handle)))
+	if contributorData.authorCompany != "" {
+		strBuffer.WriteString(fmt.Sprintf(" (%s)", html.EscapeString(contributorData.authorCompany)))
+	}
+	strBuffer.WriteString(fmt.Sprintf(" submitted %s pull request(s) in %s", contributorData.totalPRs_found, displayMonth(contributorData.month)))
+
+	if len(contributorData.repositoryPRs) > 0 {
+		var links []string
+		for _, repository := range contributorData.repositoryPRs {
+			links = append(links, fmt.Sprintf("<a href=\"%s\">%s</a> (%d)", repositoryURL(repository.name), html.EscapeString(repository.name), repository.prs))
+		}
+		strBuffer.WriteString(" to " + strings.Join(links, ", "))
+	}
+	strBuffer.WriteString(".</p>\n")
+
+	if len(contributorData.pullRequests) > 0 {
+		strBuffer.WriteString("  <ul>\n")
+		for _, pr := range contributorData.pullRequests {
+			strBuffer.WriteString(fmt.Sprintf("    <li><a href=\"%s\">%s</a> (%s)</li>\n", html.EscapeString(pr.url), html.EscapeString(pr.title), html.EscapeString(pr.repository)))
+		}
+		strBuffer.WriteString("  </ul>\n")
+	}
+	strBuffer.WriteString("</div>\n")
+	return strBuffer.String()
+}
+
+// Generates a short text announcing the honored contributor (for social media)
+func formatHonorAnnouncement(contributorData HonoredContributorData) string {
+	var strBuffer strings.Builder
+	strBuffer.WriteString(fmt.Sprintf("Thank you %s for your %s pull request(s) to the Jenkins project in %s!",
+		displayName(contributorData), contributorData.totalPRs_found, displayMonth(contributorData.month)))
+	if len(contributorData.repositoryPRs) > 0 {
+		strBuffer.WriteString(fmt.Sprintf(" Contributions to %s.", strings.Join(repositoryNames(contributorData.repositoryPRs), ", ")))
+	}
+	strBuffer.WriteString(" Learn more about our contributors at https://contributors.jenkins.io/ #Jenkins #OpenSource\n")
+	return strBuffer.String()
+}
+
+// Returns the name and the handle of the contributor ("Name (@handle)" or "@handle" if the name is unknown)
+func displayName(contributorData HonoredContributorData) string {
+	if contributorData.fullName == "" {
+		return "@" + contributorData.handle
+	}
+	return fmt.Sprintf("%s (@%s)", contributorData.fullName, contributorData.handle)
+}
+
+// Returns the month in a readable form ("April 2024")
+func displayMonth(month string) string {
+	monthDate, err := time.Parse("2006-01", month)
+	if err != nil {
+		return month
+	}
+	return monthDate.Format("January 2006")
+}
+
+// Returns the GitHub URL of a repository ("org/project")
+func repositoryURL(repository string) string {
+	return "https://github.com/" + repository
+}
+
+// Escapes the characters that would break the Markdown links, emphasis and tables (or be taken as HTML)
+func escapeMarkdown(text string) string {
+	replacer := strings.NewReplacer("\\", "\\\\", "[", "\\[", "]", "\\]", "*", "\\*", "_", "\\_", "`", "\\`",
+		"|", "\\|", "<", "\\<", ">", "\\>")
+	return replacer.Replace(text)
+}
+
+// Encodes the characters that would end a Markdown link destination
+func escapeMarkdownURL(url string) string {
+	replacer := strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29", "<", "%3C", ">", "%3E")
+	return replacer.Replace(url)
+}
@@ -0,0 +1,195 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var honorOutputTestData = HonoredContributorData{
+	handle:            "alice",
+	fullName:          "Alice Doe",
+	authorURL:         "https://github.com/alice",
+	authorAvatarUrl:   "https://avatars.githubusercontent.com/u/1001",
+	authorCompany:     "ACME",
+	month:             "2024-04",
+	totalPRs_found:    "2",
+	totalPRs_expected: "2",
+	repositories:      "jenkinsci/jenkins jenkins-infra/helpdesk",
+	seed:              "42",
+	rank:              "1",
+	reason:            "newcomer (first PR this month)",
+	repositoryPRs:     []honoredRepository{{name: "jenkinsci/jenkins", prs: 1}, {name: "jenkins-infra/helpdesk", prs: 1}},
+	pullRequests: []honoredPullRequest{
+		{url: "https://github.com/jenkinsci/jenkins/pull/9001", title: "Fix [JENKINS-1] <script>", repository: "jenkinsci/jenkins"},
+		{url: "https://github.com/jenkins-infra/helpdesk/pull/10", title: "Add a mirror", repository: "jenkins-infra/helpdesk"},
+	},
+}
+
+func Test_formatHonoredContributor_csv(t *testing.T) {
+	got, err := formatHonoredContributors([]HonoredContributorData{honorOutputTestData}, "2024-05-02T10-00-00Z", formatCSV)
+	assert.NoError(t, err)
+	assert.Equal(t, generateHonoredContributorDataCSVheader()+"\n"+
+		"\"2024-05-02T10-00-00Z\", \"2024-04\", \"alice\", \"Alice Doe\", \"ACME\", \"https://github.com/alice\", \"https://avatars.githubusercontent.com/u/1001\", \"2\", \"jenkinsci/jenkins jenkins-infra/helpdesk\", \"42\", \"1\", \"newcomer (first PR this month)\"\n", got)
+}
+
+func Test_formatHonoredContributor_json(t *testing.T) {
+	got, err := formatHonoredContributors([]HonoredContributorData{honorOutputTestData}, "2024-05-02T10-00-00Z", formatJSON)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[{
+		"run_date": "2024-05-02T10-00-00Z",
+		"month": "2024-04",
+		"handle": "alice",
+		"name": "Alice Doe",
+		"company": "ACME",
+		"url": "https://github.com/alice",
+		"avatar_url": "https://avatars.githubusercontent.com/u/1001",
+		"pull_request_count": 2,
+		"repositories": [
+			{"name": "jenkinsci/jenkins", "url": "https://github.com/jenkinsci/jenkins", "pull_request_count": 1},
+			{"name": "jenkins-infra/helpdesk", "url": "https://github.com/jenkins-infra/helpdesk", "pull_request_count": 1}
+		],
+		"pull_requests": [
+			{"title": "Fix [JENKINS-1] <script>", "url": "https://github.com/jenkinsci/jenkins/pull/9001", "repository": "jenkinsci/jenkins"},
+			{"title": "Add a mirror", "url": "https://github.com/jenkins-infra/helpdesk/pull/10", "repository": "jenkins-infra/helpdesk"}
+		],
+		"seed": "42",
+		"rank": 1,
+		"reason": "newcomer (first PR this month)"
+	}]`, got)
+
+	// empty lists rather than null
+	got, err = formatHonoredContributors([]HonoredContributorData{{handle: "bob"}}, "", formatJSON)
+	assert.NoError(t, err)
+	assert.Contains(t, got, `"repositories": []`)
+	assert.Contains(t, got, `"pull_requests": []`)
+}
+
+func Test_formatHonoredContributor_markdown(t *testing.T) {
+	got, err := formatHonoredContributors([]HonoredContributorData{honorOutputTestData}, "", formatMarkdown)
+	assert.NoError(t, err)
+	assert.Equal(t, "### Contributor of the month: Alice Doe (@alice)\n\n"+
+		"<img src=\"https://avatars.githubusercontent.com/u/1001\" alt=\"alice\" width=\"100\"/>\n\n"+
+		"[@alice](https://github.com/alice) (ACME) submitted 2 pull request(s) in April 2024 to "+
+		"[jenkinsci/jenkins](https://github.com/jenkinsci/jenkins) (1), [jenkins-infra/helpdesk](https://github.com/jenkins-infra/helpdesk) (1).\n\n"+
+		"- [Fix \\[JENKINS-1\\] \\<script\\>](https://github.com/jenkinsci/jenkins/pull/9001) (jenkinsci/jenkins)\n"+
+		"- [Add a mirror](https://github.com/jenkins-infra/helpdesk/pull/10) (jenkins-infra/helpdesk)\n", got)
+}
+
+func Test_formatHonoredContributor_markdownEscaping(t *testing.T) {
+	contributor := HonoredContributorData{
+		handle:          "jdoe",
+		fullName:        "John *JD* [Doe] | Jr",
+		authorURL:       "https://github.com/jdoe)",
+		authorAvatarUrl: "https://avatars.example.com/u/1?s=\"100\"",
+		month:           "2024-04",
+		totalPRs_found:  "1",
+		repositoryPRs:   []honoredRepository{{name: "jenkinsci/my_plugin", prs: 1}},
+	}
+
+	got, err := formatHonoredContributors([]HonoredContributorData{contributor}, "", formatMarkdown)
+	assert.NoError(t, err)
+	assert.Equal(t, "### Contributor of the month: John \\*JD\\* \\[Doe\\] \\| Jr (@jdoe)\n\n"+
+		"<img src=\"https://avatars.example.com/u/1?s=&#34;100&#34;\" alt=\"jdoe\" width=\"100\"/>\n\n"+
+		"[@jdoe](https://github.com/jdoe%29) submitted 1 pull request(s) in April 2024 to "+
+		"[jenkinsci/my\\_plugin](https://github.com/jenkinsci/my_plugin) (1).\n", got)
+}
+
+func Test_formatHonoredContributor_html(t *testing.T) {
+	got, err := formatHonoredContributors([]HonoredContributorData{honorOutputTestData}, "", formatHTML)
+	assert.NoError(t, err)
+	assert.Equal(t, "<div class=\"honored-contributor\">\n"+
+		"  <h3>Contributor of the month: Alice Doe (@alice)</h3>\n"+
+		"  <a href=\"https://github.com/alice\"><img src=\"https://avatars.githubusercontent.com/u/1001\" alt=\"alice\" width=\"100\"/></a>\n"+
+		"  <p><a href=\"https://github.com/alice\">@alice</a> (ACME) submitted 2 pull request(s) in April 2024 to "+
+		"<a href=\"https://github.com/jenkinsci/jenkins\">jenkinsci/jenkins</a> (1), <a href=\"https://github.com/jenkins-infra/helpdesk\">jenkins-infra/helpdesk</a> (1).</p>\n"+
+		"  <ul>\n"+
+		"    <li><a href=\"https://github.com/jenkinsci/jenkins/pull/9001\">Fix [JENKINS-1] &lt;script&gt;</a> (jenkinsci/jenkins)</li>\n"+
+		"    <li><a href=\"https://github.com/jenkins-infra/helpdesk/pull/10\">Add a mirror</a> (jenkins-infra/helpdesk)</li>\n"+
+		"  </ul>\n"+
+		"</div>\n", got)
+}
+
+func Test_formatHonoredContributors_several(t *testing.T) {
+	second := HonoredContributorData{handle: "bob", month: "2024-04", totalPRs_found: "1", seed: "42", rank: "2", reason: "random draw among 3 contributors"}
+
+	got, err := formatHonoredContributors([]HonoredContributorData{honorOutputTestData, second}, "2024-05-02T10-00-00Z", formatCSV)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(strings.Split(strings.TrimSuffix(got, "\n"), "\n")), "A header and a line per contributor expected")
+	assert.True(t, strings.HasSuffix(got, "\"42\", \"2\", \"random draw among 3 contributors\"\n"))
+
+	got, err = formatHonoredContributors([]HonoredContributorData{honorOutputTestData, second}, "", formatJSON)
+	assert.NoError(t, err)
+	var decoded []map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(got), &decoded))
+	assert.Equal(t, 2, len(decoded))
+	assert.Equal(t, "bob", decoded[1]["handle"])
+	assert.Equal(t, float64(2), decoded[1]["rank"])
+
+	got, err = formatHonoredContributors([]HonoredContributorData{honorOutputTestData, second}, "", formatMarkdown)
+	assert.NoError(t, err)
+	assert.Contains(t, got, "pull/10) (jenkins-infra/helpdesk)\n\n### Contributor of the month: @bob\n")
+
+	got, err = formatHonoredContributors([]HonoredContributorData{honorOutputTestData, second}, "", formatHTML)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(got, "<div class=\"honored-contributor\">"))
+}
+
+func Test_formatHonoredContributor_unsupported(t *testing.T) {
+	_, err := formatHonoredContributors([]HonoredContributorData{honorOutputTestData}, "", "pdf")
+	assert.Error(t, err)
+}
+
+func Test_formatHonorAnnouncement(t *testing.T) {
+	assert.Equal(t, "Thank you Alice Doe (@alice) for your 2 pull request(s) to the Jenkins project in April 2024!"+
+		" Contributions to jenkinsci/jenkins, jenkins-infra/helpdesk."+
+		" Learn more about our contributors at https://contributors.jenkins.io/ #Jenkins #OpenSource\n",
+		formatHonorAnnouncement(honorOutputTestData))
+	assert.Equal(t, "Thank you @bob for your 1 pull request(s) to the Jenkins project in May 2024!"+
+		" Learn more about our contributors at https://contributors.jenkins.io/ #Jenkins #OpenSource\n",
+		formatHonorAnnouncement(HonoredContributorData{handle: "bob", month: "2024-05", totalPRs_found: "1"}))
+}
+
+func Test_displayMonth(t *testing.T) {
+	assert.Equal(t, "April 2024", displayMonth("2024-04"))
+	assert.Equal(t, "junk", displayMonth("junk"))
+}
+
+func Test_escapeMarkdown(t *testing.T) {
+	assert.Equal(t, "\\[JENKINS-1\\] fix \\*all\\* \\_things\\_ \\`now\\`", escapeMarkdown("[JENKINS-1] fix *all* _things_ `now`"))
+	assert.Equal(t, "a \\| b \\<br\\> c\\\\", escapeMarkdown("a | b <br> c\\"))
+}
+
+func Test_escapeMarkdownURL(t *testing.T) {
+	assert.Equal(t, "https://github.com/jenkinsci/jenkins/pull/9001", escapeMarkdownURL("https://github.com/jenkinsci/jenkins/pull/9001"))
+	assert.Equal(t, "https://example.com/a%20b%28c%29", escapeMarkdownURL("https://example.com/a b(c)"))
+}
+
+func Test_performHonorContributorSelection_invalidFormat(t *testing.T) {
+	parameters := newTestHonorParameters(42)
+	parameters.outputFormat = "pdf"
+	assert.ErrorContains(t, performHonorContributorSelection("../test-data/monthly", "", "2024-02", parameters), "Unsupported output format")
+}
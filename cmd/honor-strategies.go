@@ -0,0 +1,338 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The selection strategies of the "honor" command
+const (
+	strategyUniform   = "uniform"
+	strategyWeighted  = "weighted"
+	strategyNewcomers = "newcomers"
+	strategyRepos     = "repos"
+)
+
+// How much more likely a favoured contributor (a newcomer) is to be picked
+const honorFavourFactor = 3.0
+
+// A contributor that can be honored, with their activity of the month
+type honorCandidate struct {
+	user         string
+	prs          int
+	mergedPRs    int
+	comments     int
+	repositories []string
+	isNewcomer   bool
+}
+
+// The information about the candidates that the strategies can use
+type honorSelectionContext struct {
+	contributorsPerRepo map[string]int
+	nbrOfCandidates     int // the candidates of the draw (once excluded, honored recently or already drawn removed)
+}
+
+// Returns the selection context of a draw among the given candidates
+func (c honorSelectionContext) forCandidates(candidates []honorCandidate) honorSelectionContext {
+	c.nbrOfCandidates = len(candidates)
+	return c
+}
+
+// Computes the weight of a candidate: the higher the weight, the more likely the candidate is picked
+type honorWeightFunction func(candidate honorCandidate, selectionContext honorSelectionContext) float64
+
+// Explains why a picked candidate was selected (written in the output)
+type honorReasonFunction func(candidate honorCandidate, selectionContext honorSelectionContext) string
+
+// A selection strategy: how the candidates are weighted and how their selection is explained
+type honorSelectionStrategy struct {
+	weight honorWeightFunction
+	reason honorReasonFunction
+}
+
+// The available selection strategies. A new strategy is added by registering its functions.
+var honorStrategies = map[string]honorSelectionStrategy{
+	// every candidate has the same chance
+	strategyUniform: {
+		weight: func(candidate honorCandidate, selectionContext honorSelectionContext) float64 {
+			return 1
+		},
+		reason: func(candidate honorCandidate, selectionContext honorSelectionContext) string {
+			return fmt.Sprintf("random draw among %d contributors", selectionContext.nbrOfCandidates)
+		},
+	},
+	// the more PRs and comments, the more chances
+	strategyWeighted: {
+		weight: func(candidate honorCandidate, selectionContext honorSelectionContext) float64 {
+			return float64(candidate.prs + candidate.comments)
+		},
+		reason: func(candidate honorCandidate, selectionContext honorSelectionContext) string {
+			return fmt.Sprintf("draw weighted by activity (%d PRs, %d comments)", candidate.prs, candidate.comments)
+		},
+	},
+	// the contributors whose first PR was submitted this month have more chances
+	strategyNewcomers: {
+		weight: func(candidate honorCandidate, selectionContext honorSelectionContext) float64 {
+			if candidate.isNewcomer {
+				return honorFavourFactor
+			}
+			return 1
+		},
+		reason: func(candidate honorCandidate, selectionContext honorSelectionContext) string {
+			if candidate.isNewcomer {
+				return "newcomer (first PR this month)"
+			}
+			return "draw favouring newcomers"
+		},
+	},
+	// the contributors of repositories with few contributors have more chances
+	strategyRepos: {
+		weight: func(candidate honorCandidate, selectionContext honorSelectionContext) float64 {
+			weight := 0.0
+			if repository := leastContributedRepository(candidate, selectionContext); repository != "" {
+				weight = 1 / float64(selectionContext.contributorsPerRepo[repository])
+			}
+			if weight == 0 && selectionContext.nbrOfCandidates > 0 {
+				// nothing known about the candidate's repositories: as if they were all in the same one
+				weight = 1 / float64(selectionContext.nbrOfCandidates)
+			}
+			return weight
+		},
+		reason: func(candidate honorCandidate, selectionContext honorSelectionContext) string {
+			repository := leastContributedRepository(candidate, selectionContext)
+			if repository == "" {
+				return "draw favouring under-represented repositories"
+			}
+			return fmt.Sprintf("contributor of %s (%d contributors this month)", repository, selectionContext.contributorsPerRepo[repository])
+		},
+	},
+}
+
+// Returns the candidate's repository having the fewest contributors (empty if none is known)
+func leastContributedRepository(candidate honorCandidate, selectionContext honorSelectionContext) string {
+	found := ""
+	for _, repository := range candidate.repositories {
+		nbrOfContributors := selectionContext.contributorsPerRepo[repository]
+		if nbrOfContributors > 0 && (found == "" || nbrOfContributors < selectionContext.contributorsPerRepo[found]) {
+			found = repository
+		}
+	}
+	return found
+}
+
+// Checks that the strategy is one of the registered ones
+func validateHonorStrategy(strategy string) error {
+	if _, ok := honorStrategies[strategy]; !ok {
+		return fmt.Errorf("Unsupported selection strategy \"%s\" (should be one of %s)", strategy, prettyPrintStringList(listHonorStrategies()))
+	}
+	return nil
+}
+
+// Returns the names of the registered strategies, sorted
+func listHonorStrategies() []string {
+	var strategies []string
+	for name := range honorStrategies {
+		strategies = append(strategies, name)
+	}
+	sort.Strings(strategies)
+	return strategies
+}
+
+// Returns true if the strategy (or the minimum merged PRs) needs the details of the submissions files
+func isSubmissionDetailNeeded(strategy string, minMergedPRs int) bool {
+	return strategy == strategyNewcomers || strategy == strategyRepos || minMergedPRs > 0
+}
+
+// Builds the list of candidates from the submitters of the month ("pr_per_submitter" file),
+// completed with the details of the submissions file and, if requested, with the commenters.
+func loadHonorCandidates(table csvTable, dataDir string, month string, isIncludeCommenters bool, isDetailNeeded bool) ([]honorCandidate, honorSelectionContext, error) {
+	var candidates []honorCandidate
+	var selectionContext honorSelectionContext
+	indexByUser := make(map[string]int)
+
+	for _, record := range table.records {
+		user := table.get(record, "user")
+		prs, err := strconv.Atoi(table.get(record, "PR"))
+		if err != nil {
+			return nil, selectionContext, fmt.Errorf("Invalid number of PRs for \"%s\": %v", user, err)
+		}
+		// The PRs of the different accounts of a person are added up
+		user = userAliases.canonical(user)
+		if i, exists := indexByUser[user]; exists {
+			candidates[i].prs += prs
+			continue
+		}
+		indexByUser[user] = len(candidates)
+		candidates = append(candidates, honorCandidate{user: user, prs: prs})
+	}
+
+	// Users contributing to each repository (submitters and, if included, commenters)
+	repositoryUsers := make(map[string]map[string]bool)
+	addRepositoryUser := func(repository string, user string) {
+		if repositoryUsers[repository] == nil {
+			repositoryUsers[repository] = make(map[string]bool)
+		}
+		repositoryUsers[repository][user] = true
+	}
+
+	if isDetailNeeded {
+		submissions, err := loadSubmissionsForPeriod(dataDir, month, month, nil)
+		if err != nil {
+			return nil, selectionContext, err
+		}
+		history, err := loadSubmissionsHistory(dataDir, month, nil)
+		if err != nil {
+			return nil, selectionContext, err
+		}
+		firstContributions := computeFirstContributions(history)
+
+		for _, submission := range submissions {
+			addRepositoryUser(submission.repositorySpec(), submission.user)
+			i, ok := indexByUser[submission.user]
+			if !ok {
+				continue
+			}
+			if submission.isMerged() {
+				candidates[i].mergedPRs++
+			}
+			candidates[i].repositories = appendIfMissing(candidates[i].repositories, submission.repositorySpec())
+			candidates[i].isNewcomer = firstContributions[submission.user].month == month
+		}
+	}
+
+	if isIncludeCommenters {
+		comments, err := loadCommentsForPeriod(dataDir, month, month, nil)
+		if err != nil {
+			return nil, selectionContext, err
+		}
+		if len(comments) == 0 {
+			fmt.Fprintf(os.Stderr, "Warning: no commenters found for %s in \"%s\"\n", month, dataDir)
+		}
+		for _, comment := range comments {
+			repository := repositoryOfPRref(comment.prRef)
+			addRepositoryUser(repository, comment.commenter)
+			i, ok := indexByUser[comment.commenter]
+			if !ok {
+				i = len(candidates)
+				indexByUser[comment.commenter] = i
+				candidates = append(candidates, honorCandidate{user: comment.commenter})
+			}
+			candidates[i].comments++
+			candidates[i].repositories = appendIfMissing(candidates[i].repositories, repository)
+		}
+	}
+
+	selectionContext.contributorsPerRepo = make(map[string]int)
+	for repository, users := range repositoryUsers {
+		selectionContext.contributorsPerRepo[repository] = len(users)
+	}
+	return candidates, selectionContext, nil
+}
+
+// Returns the repository ("org/project") of a PR reference ("org/project/pr_nbr")
+func repositoryOfPRref(prRef string) string {
+	if i := strings.LastIndex(prRef, "/"); i > 0 {
+		return prRef[:i]
+	}
+	return prRef
+}
+
+// Adds an item to the slice only if it is not there yet
+func appendIfMissing(list []string, item string) []string {
+	for _, existing := range list {
+		if existing == item {
+			return list
+		}
+	}
+	return append(list, item)
+}
+
+// Returns the candidates that have at least the given number of merged PRs
+func filterMinimumMerged(candidates []honorCandidate, minMergedPRs int) []honorCandidate {
+	var eligible []honorCandidate
+	for _, candidate := range candidates {
+		if candidate.mergedPRs >= minMergedPRs {
+			eligible = append(eligible, candidate)
+		}
+	}
+	return eligible
+}
+
+// Picks one of the candidates according to the strategy. The same seed always gives the same pick.
+func selectHonorCandidate(candidates []honorCandidate, strategy string, selectionContext honorSelectionContext, seed uint64) (int, error) {
+	selectedStrategy, ok := honorStrategies[strategy]
+	if !ok {
+		return 0, validateHonorStrategy(strategy)
+	}
+	if len(candidates) == 0 {
+		return 0, fmt.Errorf("No candidate to select from")
+	}
+
+	selectionContext = selectionContext.forCandidates(candidates)
+	var weights []float64
+	for _, candidate := range candidates {
+		weights = append(weights, selectedStrategy.weight(candidate, selectionContext))
+	}
+	return selectWeightedRecord(weights, seed)
+}
+
+// Picks randomly one of the records, proportionally to their weight.
+// When all the weights are equal, the pick is the same as a uniform selection with the same seed.
+func selectWeightedRecord(weights []float64, seed uint64) (int, error) {
+	total := 0.0
+	isUniform := true
+	for _, weight := range weights {
+		if weight < 0 {
+			return 0, fmt.Errorf("Invalid negative selection weight")
+		}
+		total += weight
+		isUniform = isUniform && weight == weights[0]
+	}
+	if total <= 0 {
+		return 0, fmt.Errorf("No candidate has a chance to be selected")
+	}
+	if isUniform {
+		return selectRandomRecord(len(weights), seed), nil
+	}
+
+	random := rand.New(rand.NewPCG(seed, seed))
+	target := random.Float64() * total
+	lastEligible := 0
+	for i, weight := range weights {
+		if weight == 0 {
+			continue
+		}
+		if target < weight {
+			return i, nil
+		}
+		target -= weight
+		lastEligible = i
+	}
+	// rounding errors
+	return lastEligible, nil
+}
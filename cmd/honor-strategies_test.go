@@ -0,0 +1,230 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_validateHonorStrategy(t *testing.T) {
+	for _, strategy := range []string{strategyUniform, strategyWeighted, strategyNewcomers, strategyRepos} {
+		assert.NoError(t, validateHonorStrategy(strategy))
+	}
+	assert.Error(t, validateHonorStrategy("junk"))
+	assert.Error(t, validateHonorStrategy(""))
+}
+
+func Test_listHonorStrategies(t *testing.T) {
+	assert.Equal(t, []string{"newcomers", "repos", "uniform", "weighted"}, listHonorStrategies())
+}
+
+func Test_isSubmissionDetailNeeded(t *testing.T) {
+	assert.False(t, isSubmissionDetailNeeded(strategyUniform, 0))
+	assert.False(t, isSubmissionDetailNeeded(strategyWeighted, 0))
+	assert.True(t, isSubmissionDetailNeeded(strategyUniform, 1))
+	assert.True(t, isSubmissionDetailNeeded(strategyNewcomers, 0))
+	assert.True(t, isSubmissionDetailNeeded(strategyRepos, 0))
+}
+
+func Test_loadHonorCandidates(t *testing.T) {
+	table, err := loadCSVtable("../test-data/monthly/pr_per_submitter-2024-02.csv")
+	assert.NoError(t, err)
+
+	candidates, selectionContext, err := loadHonorCandidates(table, "../test-data/monthly", "2024-02", true, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []honorCandidate{
+		{user: "bob", prs: 1, mergedPRs: 1, repositories: []string{"jenkinsci/jenkins"}},
+		{user: "dave", prs: 1, mergedPRs: 1, repositories: []string{"jenkinsci/git-plugin"}, isNewcomer: true},
+		{user: "alice", prs: 1, mergedPRs: 1, comments: 2, repositories: []string{"jenkinsci/jenkins", "jenkinsci/git-plugin"}},
+		{user: "frank", comments: 1, repositories: []string{"jenkinsci/git-plugin"}},
+	}, candidates)
+	assert.Equal(t, honorSelectionContext{
+		contributorsPerRepo: map[string]int{"jenkinsci/jenkins": 2, "jenkinsci/git-plugin": 3},
+	}, selectionContext)
+}
+
+func Test_loadHonorCandidates_submittersOnly(t *testing.T) {
+	table, err := loadCSVtable("../test-data/monthly/pr_per_submitter-2024-02.csv")
+	assert.NoError(t, err)
+
+	candidates, selectionContext, err := loadHonorCandidates(table, "../test-data/monthly", "2024-02", false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []honorCandidate{{user: "bob", prs: 1}, {user: "dave", prs: 1}, {user: "alice", prs: 1}}, candidates)
+	assert.Empty(t, selectionContext.contributorsPerRepo)
+}
+
+func Test_loadHonorCandidates_withAliases(t *testing.T) {
+	var err error
+	userAliases, err = loadIdentityAliases("../test-data/aliases.txt")
+	assert.NoError(t, err)
+	defer func() { userAliases = identityAliases{} }()
+
+	table, err := readCSVtable(bytes.NewBufferString("user,PR\nerin,2\nbob,1\ndave,1\n"))
+	assert.NoError(t, err)
+
+	candidates, _, err := loadHonorCandidates(table, "../test-data/monthly", "2024-03", false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []honorCandidate{{user: "dave", prs: 3}, {user: "bob", prs: 1}}, candidates, "erin is dave's former account")
+}
+
+func Test_loadHonorCandidates_errors(t *testing.T) {
+	table, err := loadCSVtable("../test-data/monthly/pr_per_submitter-2024-02.csv")
+	assert.NoError(t, err)
+
+	_, _, err = loadHonorCandidates(table, "../test-data/monthly", "2024-05", false, true)
+	assert.Error(t, err, "No submissions file for the month")
+
+	table, err = readCSVtable(bytes.NewBufferString("user,PR\nbob,many\n"))
+	assert.NoError(t, err)
+	_, _, err = loadHonorCandidates(table, "../test-data/monthly", "2024-02", false, false)
+	assert.Error(t, err, "Invalid number of PRs")
+}
+
+func Test_honorStrategies_weights(t *testing.T) {
+	selectionContext := honorSelectionContext{
+		contributorsPerRepo: map[string]int{"jenkinsci/jenkins": 4, "jenkinsci/git-plugin": 2},
+		nbrOfCandidates:     5,
+	}
+	prolific := honorCandidate{user: "bob", prs: 5, comments: 3, repositories: []string{"jenkinsci/jenkins"}}
+	newcomer := honorCandidate{user: "dave", prs: 1, repositories: []string{"jenkinsci/jenkins", "jenkinsci/git-plugin"}, isNewcomer: true}
+	unknown := honorCandidate{user: "frank", prs: 1}
+
+	tests := []struct {
+		strategy string
+		want     []float64
+	}{
+		{strategyUniform, []float64{1, 1, 1}},
+		{strategyWeighted, []float64{8, 1, 1}},
+		{strategyNewcomers, []float64{1, honorFavourFactor, 1}},
+		{strategyRepos, []float64{0.25, 0.5, 0.2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			weightFunction := honorStrategies[tt.strategy].weight
+			var got []float64
+			for _, candidate := range []honorCandidate{prolific, newcomer, unknown} {
+				got = append(got, weightFunction(candidate, selectionContext))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_filterMinimumMerged(t *testing.T) {
+	candidates := []honorCandidate{{user: "bob", mergedPRs: 2}, {user: "dave", mergedPRs: 1}, {user: "frank"}}
+
+	assert.Equal(t, candidates, filterMinimumMerged(candidates, 0))
+	assert.Equal(t, []honorCandidate{{user: "bob", mergedPRs: 2}, {user: "dave", mergedPRs: 1}}, filterMinimumMerged(candidates, 1))
+	assert.Empty(t, filterMinimumMerged(candidates, 3))
+}
+
+func Test_repositoryOfPRref(t *testing.T) {
+	assert.Equal(t, "jenkinsci/jenkins", repositoryOfPRref("jenkinsci/jenkins/9010"))
+	assert.Equal(t, "junk", repositoryOfPRref("junk"))
+}
+
+func Test_selectWeightedRecord(t *testing.T) {
+	// equal weights give the same pick as the uniform selection
+	for seed := uint64(0); seed < 20; seed++ {
+		got, err := selectWeightedRecord([]float64{2, 2, 2, 2}, seed)
+		assert.NoError(t, err)
+		assert.Equal(t, selectRandomRecord(4, seed), got)
+	}
+
+	// a record without weight is never picked, a heavier one is picked more often
+	var picks [3]int
+	for seed := uint64(0); seed < 1000; seed++ {
+		got, err := selectWeightedRecord([]float64{1, 0, 3}, seed)
+		assert.NoError(t, err)
+		picks[got]++
+	}
+	assert.Equal(t, 0, picks[1])
+	assert.Greater(t, picks[2], picks[0])
+
+	_, err := selectWeightedRecord([]float64{0, 0}, 42)
+	assert.Error(t, err)
+	_, err = selectWeightedRecord([]float64{1, -1}, 42)
+	assert.Error(t, err)
+}
+
+func Test_selectHonorCandidate(t *testing.T) {
+	candidates := []honorCandidate{{user: "bob", prs: 1}, {user: "dave", prs: 1, isNewcomer: true}}
+
+	got, err := selectHonorCandidate(candidates, strategyNewcomers, honorSelectionContext{}, 42)
+	assert.NoError(t, err)
+	again, _ := selectHonorCandidate(candidates, strategyNewcomers, honorSelectionContext{}, 42)
+	assert.Equal(t, got, again, "The selection should be reproducible")
+
+	_, err = selectHonorCandidate(candidates, "junk", honorSelectionContext{}, 42)
+	assert.Error(t, err)
+	_, err = selectHonorCandidate(nil, strategyUniform, honorSelectionContext{}, 42)
+	assert.Error(t, err)
+}
+
+func Test_performHonorContributorSelection_noEligibleCandidate(t *testing.T) {
+	parameters := newTestHonorParameters(42)
+	parameters.strategy = "junk"
+	err := performHonorContributorSelection("../test-data", "", "2024-04", parameters)
+	assert.ErrorContains(t, err, "Unsupported selection strategy")
+
+	parameters = newTestHonorParameters(42)
+	parameters.minMergedPRs = 2
+	err = performHonorContributorSelection("../test-data/monthly", "", "2024-02", parameters)
+	assert.ErrorContains(t, err, "--min_merged")
+
+	// bob, the only one with 2 merged PRs in 2024-03, was honored for 2024-01
+	err = performHonorContributorSelection("../test-data/monthly", "", "2024-03", parameters)
+	assert.ErrorContains(t, err, "--cooldown")
+}
+
+func Test_honorStrategies_reasons(t *testing.T) {
+	selectionContext := honorSelectionContext{
+		contributorsPerRepo: map[string]int{"jenkinsci/jenkins": 4, "jenkinsci/git-plugin": 2},
+		nbrOfCandidates:     5,
+	}
+	prolific := honorCandidate{user: "bob", prs: 5, comments: 3, repositories: []string{"jenkinsci/jenkins"}}
+	newcomer := honorCandidate{user: "dave", prs: 1, repositories: []string{"jenkinsci/jenkins", "jenkinsci/git-plugin"}, isNewcomer: true}
+	unknown := honorCandidate{user: "frank", prs: 1}
+
+	tests := []struct {
+		strategy string
+		want     []string
+	}{
+		{strategyUniform, []string{"random draw among 5 contributors", "random draw among 5 contributors", "random draw among 5 contributors"}},
+		{strategyWeighted, []string{"draw weighted by activity (5 PRs, 3 comments)", "draw weighted by activity (1 PRs, 0 comments)", "draw weighted by activity (1 PRs, 0 comments)"}},
+		{strategyNewcomers, []string{"draw favouring newcomers", "newcomer (first PR this month)", "draw favouring newcomers"}},
+		{strategyRepos, []string{"contributor of jenkinsci/jenkins (4 contributors this month)", "contributor of jenkinsci/git-plugin (2 contributors this month)", "draw favouring under-represented repositories"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			reasonFunction := honorStrategies[tt.strategy].reason
+			var got []string
+			for _, candidate := range []honorCandidate{prolific, newcomer, unknown} {
+				got = append(got, reasonFunction(candidate, selectionContext))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -23,8 +23,9 @@ package cmd
 
 import (
 	"context"
-	"encoding/csv"
+	"errors"
 	"fmt"
+	"hash/fnv"
 	"math/rand/v2"
 	"os"
 	"path/filepath"
@@ -39,6 +40,49 @@ import (
 
 var honorDataDir string
 var honorOutput string
+var honorSeed uint64
+var honorCooldownMonths int
+var honorStrategy string
+var honorMinMergedPRs int
+var honorIsIncludeCommenters bool
+var honorExcludeFileName string
+var honorOrgs []string
+var honorOnMismatch string
+var honorFormat string
+var honorAnnouncementFileName string
+var honorCount int
+var honorIsPerOrg bool
+var honorIsOffline bool
+
+// The organizations where the PRs of the honored contributor are searched (see "--orgs")
+var defaultHonorOrgs = []string{"jenkinsci", "jenkins-infra", "jenkins-docs"}
+
+// What to do when the number of PRs found on GitHub is not the one of the data file (see "--on_mismatch")
+const (
+	mismatchWarn   = "warn"
+	mismatchRedraw = "redraw"
+	mismatchAccept = "accept"
+)
+
+// The parameters of the selection of the contributor to honor
+type honorSelectionParameters struct {
+	seed                uint64
+	cooldownMonths      int
+	strategy            string
+	minMergedPRs        int
+	isIncludeCommenters bool
+	excludedUsers       []string
+	orgs                []string
+	onMismatch          string
+	// how the honored contributor is written
+	outputFormat         string
+	announcementFileName string
+	// how many contributors are honored
+	count    int
+	isPerOrg bool
+	// no GitHub query: the PRs come from the submissions file of the month
+	isOffline bool
+}
 
 type HonoredContributorData struct {
 	handle            string
@@ -50,6 +94,24 @@ type HonoredContributorData struct {
 	totalPRs_found    string
 	totalPRs_expected string
 	repositories      string
+	seed              string
+	rank              string
+	reason            string
+	repositoryPRs     []honoredRepository
+	pullRequests      []honoredPullRequest
+}
+
+// A repository the honored contributor submitted PRs to
+type honoredRepository struct {
+	name string
+	prs  int
+}
+
+// A PR of the honored contributor
+type honoredPullRequest struct {
+	url        string
+	title      string
+	repository string
 }
 
 // honorCmd represents the honor command
@@ -60,7 +122,53 @@ var honorCmd = &cobra.Command{
 format his data in such a way that it can be used to format an honoring
 message at the bottom of the https://contributors.jenkins.io/ page.
 
-\"month\" is a required parameter. It is in YYYY-MM format.`,
+\"month\" is a required parameter. It is in YYYY-MM format.
+
+The contributor's profile (name, company, avatar) is taken from the user profile cache
+("user_profiles.csv" in the data directory) and retrieved from GitHub when missing or
+expired (see the "profiles" command).
+
+The selection is reproducible: the random generator is initialized with a seed that
+defaults to a hash of the month. Another seed can be given with "--seed". The seed is
+written in the output file (SEED column) so that the selection can be audited.
+
+Each selection is appended to the honor history ("honor_history.csv" in the data
+directory, see "honor history"). Running a month again replaces the rows it had already
+recorded for the same contributors. A contributor honored during the "--cooldown" months
+preceding the month can't be picked again (0 disables the cool-down).
+
+How the contributor is picked depends on the "--strategy":
+- "uniform": all the submitters of the month have the same chance (default)
+- "weighted": the chances are proportional to the number of PRs (and comments)
+- "newcomers": the submitters whose first PR was created in the month have more chances
+- "repos": the contributors of repositories with few contributors have more chances
+With "--with_commenters", the commenters of the month ("commenters-YYYY-MM.csv") can be
+picked too. With "--min_merged", only the contributors with at least that number of merged
+PRs are eligible. "newcomers", "repos" and "--min_merged" need the submissions file of the
+month ("submissions-YYYY-MM.csv") in the data directory.
+
+The users listed in the "--excludeFile" (same format as for the "get" commands) are never
+picked. Neither are the bots, organizations and deleted accounts: when the picked user is
+not a GitHub user account, another contributor is drawn.
+
+The PRs of the honored contributor are searched in the "--orgs" organizations. When their
+number is not the one of the data file (the data was extracted from other organizations or
+has changed since), "--on_mismatch" tells whether to warn and continue ("warn", default),
+to draw another contributor ("redraw") or to silently continue ("accept").
+
+The output is a CSV file by default. With "--format", it can be written as JSON, as a
+Markdown ("md") or as an HTML snippet, ready to be published on the contributors.jenkins.io
+site (name, avatar, links to the profile and repositories, list of PRs). A short text
+announcing the honored contributor on social media can be written with "--announcement".
+The history file is always a CSV file.
+
+Several distinct contributors can be honored at once with "--count" (or "--count" per
+organization of "--orgs" with "--per_org"). They are written with their rank and the reason
+of their selection. The draws are reproducible too: the n-th draw uses the seed plus n-1.
+
+With "--offline", GitHub is not queried (no token is needed): the PRs and repositories are
+taken from the submissions file of the month ("submissions-YYYY-MM.csv", generated by "get
+submitters") and the contributor's profile from the user profile cache, when available.`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		//call requires two parameters (org and month)
 		if err := cobra.MinimumNArgs(1)(cmd, args); err != nil {
@@ -73,24 +181,81 @@ message at the bottom of the https://contributors.jenkins.io/ page.
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return performHonorContributorSelection(honorDataDir, honorOutput, args[0])
+		seed := honorSeed
+		if !cmd.Flags().Changed("seed") {
+			seed = computeDefaultHonorSeed(args[0])
+		}
+		var excludedUsers []string
+		if honorExcludeFileName != "" {
+			var err error
+			err, excludedUsers = load_exclusions(honorExcludeFileName)
+			if err != nil {
+				return fmt.Errorf("invalid excluded user list => %v\n", err)
+			}
+		}
+		parameters := honorSelectionParameters{
+			seed:                 seed,
+			cooldownMonths:       honorCooldownMonths,
+			strategy:             honorStrategy,
+			minMergedPRs:         honorMinMergedPRs,
+			isIncludeCommenters:  honorIsIncludeCommenters,
+			excludedUsers:        excludedUsers,
+			orgs:                 honorOrgs,
+			onMismatch:           honorOnMismatch,
+			outputFormat:         honorFormat,
+			announcementFileName: honorAnnouncementFileName,
+			count:                honorCount,
+			isPerOrg:             honorIsPerOrg,
+			isOffline:            honorIsOffline,
+		}
+		return performHonorContributorSelection(honorDataDir, honorOutput, args[0], parameters)
 	},
 }
 
 // Initialize command parameters and defaults
 func init() {
 	rootCmd.AddCommand(honorCmd)
-	honorCmd.Flags().StringVarP(&honorDataDir, "data_dir", "", "data", "Directory containing the data to be read")
-	honorCmd.Flags().StringVarP(&honorOutput, "output", "", "", "File to output the data to (default: \"[data_dir]/honored_contributor.csv\")")
+	honorCmd.PersistentFlags().StringVarP(&honorDataDir, "data_dir", "", "data", "Directory containing the data to be read")
+	honorCmd.Flags().StringVarP(&honorOutput, "output", "", "", "File to output the data to (default: \"[data_dir]/honored_contributor.[format]\")")
+	honorCmd.Flags().StringVarP(&honorFormat, "format", "f", formatCSV, "Output format (csv, json, md or html)")
+	honorCmd.Flags().StringVarP(&honorAnnouncementFileName, "announcement", "", "", "File to write a social media announcement text to (\"-\" for the standard output)")
+	honorCmd.Flags().Uint64VarP(&honorSeed, "seed", "", 0, "Seed of the random selection (default: a hash of the month)")
+	honorCmd.Flags().IntVarP(&honorCooldownMonths, "cooldown", "", defaultHonorCooldownMonths, "Number of months during which an honored contributor can't be picked again (0: no cool-down)")
+	honorCmd.Flags().StringVarP(&honorStrategy, "strategy", "", strategyUniform, "Selection strategy ("+strings.Join(listHonorStrategies(), ", ")+")")
+	honorCmd.Flags().IntVarP(&honorMinMergedPRs, "min_merged", "", 0, "Minimum number of merged PRs in the month to be eligible")
+	honorCmd.Flags().BoolVarP(&honorIsIncludeCommenters, "with_commenters", "", false, "The commenters of the month can be picked too")
+	honorCmd.Flags().StringVarP(&honorExcludeFileName, "excludeFile", "x", "", "Name of the file containing the github handles that can't be honored.")
+	honorCmd.Flags().StringSliceVarP(&honorOrgs, "orgs", "", defaultHonorOrgs, "GitHub organizations where the contributor's PRs are searched")
+	honorCmd.Flags().StringVarP(&honorOnMismatch, "on_mismatch", "", mismatchWarn, "What to do when the number of PRs found differs from the data (warn, redraw or accept)")
+	honorCmd.Flags().IntVarP(&honorCount, "count", "", 1, "Number of distinct contributors to honor")
+	honorCmd.Flags().BoolVarP(&honorIsPerOrg, "per_org", "", false, "Honor \"--count\" contributors per organization (needs the submissions file of the month)")
+	honorCmd.Flags().BoolVarP(&honorIsOffline, "offline", "", false, "Don't query GitHub: use the submissions file of the month and the cached profiles")
+	honorCmd.Flags().IntVarP(&profileTTLdays, "profile_ttl", "", defaultProfileTTLdays, "Number of days after which a cached profile is retrieved again (0: never)")
 }
 
 // Command processing entry point
-func performHonorContributorSelection(dataDir string, suppliedOutputFileName string, monthToSelectFrom string) error {
+func performHonorContributorSelection(dataDir string, suppliedOutputFileName string, monthToSelectFrom string, parameters honorSelectionParameters) error {
 	// validate the month
 	if !isValidMonthFormat(monthToSelectFrom) {
 		return fmt.Errorf("\"%s\" is not a valid month.", monthToSelectFrom)
 	}
 
+	if err := validateHonorStrategy(parameters.strategy); err != nil {
+		return err
+	}
+	if err := validateHonorOrgs(parameters.orgs); err != nil {
+		return err
+	}
+	if err := validateMismatchPolicy(parameters.onMismatch); err != nil {
+		return err
+	}
+	if err := validateOutputFormat(parameters.outputFormat, []string{formatCSV, formatJSON, formatMarkdown, formatHTML}); err != nil {
+		return err
+	}
+	if parameters.count < 1 {
+		return fmt.Errorf("At least one contributor must be honored (\"--count\" is %d)", parameters.count)
+	}
+
 	// does the dataDir exist ?
 	if !isValidDir(dataDir) {
 		return fmt.Errorf("Supplied DataDir \"%s\" does not exist.", dataDir)
@@ -99,107 +264,398 @@ func performHonorContributorSelection(dataDir string, suppliedOutputFileName str
 	// if output is not defined, build it
 	honorOutputFileName := ""
 	if suppliedOutputFileName == "" {
-		honorOutputFileName = filepath.Join(dataDir, "honored_contributor.csv")
+		honorOutputFileName = filepath.Join(dataDir, "honored_contributor"+outputFormatExtension(parameters.outputFormat))
 	} else {
 		honorOutputFileName = suppliedOutputFileName
 	}
 	if isVerbose {
-		fmt.Println("Output file: " + honorOutputFileName + "\n")
+		fmt.Fprintln(os.Stderr, "Output file: "+honorOutputFileName+"\n")
 	}
 
 	//compute the correct input filename (pr_per_submitter-YYYY-MM.csv)
 	inputFileName := filepath.Join(dataDir, "pr_per_submitter-"+monthToSelectFrom+".csv")
 
-	// fail if the file does not exist else open the file
-	f, err := os.Open(inputFileName)
-	if err != nil {
-		return fmt.Errorf("Unable to read input file "+inputFileName+"\n", err)
+	if isVerbose {
+		fmt.Fprintln(os.Stderr, "Checking input file "+inputFileName)
 	}
-	defer f.Close()
 
-	// validate that it has the correct format (CSV and column names)
-	r := csv.NewReader(f)
+	// fail if the file does not exist, is not a CSV or has not the expected columns
+	table, err := loadCSVtable(inputFileName)
+	if err != nil {
+		return err
+	}
 
-	headerLine, err1 := r.Read()
-	if err1 != nil {
-		return fmt.Errorf("Unexpected error loading"+inputFileName+"\n", err)
+	if table.schema.kind != schemaPrPerSubmitter {
+		return fmt.Errorf(" Error: header is incorrect (\"%s\" file instead of \"%s\").", table.schema.kind, schemaPrPerSubmitter)
+	} else {
+		if isVerbose {
+			fmt.Fprintf(os.Stderr, "  - Header is correct\n")
+		}
 	}
 
+	if len(table.records) < 1 {
+		return fmt.Errorf("Error: No data available after the header\n")
+	}
 	if isVerbose {
-		fmt.Println("Checking input file " + inputFileName)
+		fmt.Fprintln(os.Stderr, "  - At least one Submitter data available")
 	}
 
-	referencePrPerSubmitterHeader := []string{"user", "PR"}
-	if !validateHeader(headerLine, referencePrPerSubmitterHeader, isVerbose) {
-		return fmt.Errorf(" Error: header is incorrect.")
-	} else {
+	candidates, selectionContext, err := loadHonorCandidates(table, dataDir, monthToSelectFrom, parameters.isIncludeCommenters,
+		isSubmissionDetailNeeded(parameters.strategy, parameters.minMergedPRs) || parameters.isPerOrg)
+	if err != nil {
+		return err
+	}
+	candidates = filterOutCandidates(candidates, parameters.excludedUsers)
+	if len(candidates) < 1 {
+		return fmt.Errorf("All the contributors of %s are excluded (see \"--excludeFile\")", monthToSelectFrom)
+	}
+	candidates = filterMinimumMerged(candidates, parameters.minMergedPRs)
+	if len(candidates) < 1 {
+		return fmt.Errorf("No contributor of %s has at least %d merged PR(s) (see \"--min_merged\")", monthToSelectFrom, parameters.minMergedPRs)
+	}
+
+	// Skip the contributors honored recently
+	historyFileName := filepath.Join(dataDir, defaultHonorHistoryFileName)
+	history, err := loadHonorHistory(historyFileName)
+	if err != nil {
+		return err
+	}
+	recentlyHonored := computeRecentlyHonored(history, monthToSelectFrom, parameters.cooldownMonths)
+	candidates = filterOutCandidates(candidates, recentlyHonored)
+	if len(candidates) < 1 {
+		return fmt.Errorf("All the eligible contributors of %s were honored during the last %d months (see \"--cooldown\")", monthToSelectFrom, parameters.cooldownMonths)
+	}
+	if isVerbose && len(recentlyHonored) > 0 {
+		fmt.Fprintf(os.Stderr, "  - Recently honored (not eligible): %s\n", prettyPrintStringList(recentlyHonored))
+	}
+
+	// pick a candidate randomly (reproducible with the seed)
+	fmt.Fprintf(os.Stderr, "Selection seed: %d (strategy: %s)\n", parameters.seed, parameters.strategy)
+	profileCacheFileName := filepath.Join(dataDir, defaultProfileCacheFileName)
+	lookupProfile := func(login string) (userProfile, error) {
+		return lookupUserProfile(login, profileCacheFileName, profileTTLdays)
+	}
+	fetchPRs := func(submittersName string, profile userProfile, submittersPRs string) (error, HonoredContributorData) {
 		if isVerbose {
-			fmt.Printf("  - Header is correct\n")
+			fmt.Fprintf(os.Stderr, "  - Picked %s - %s PRs\nFetching data from GitHub", submittersName, submittersPRs)
 		}
+		return getSubmittersPRfromGH(submittersName, profile, submittersPRs, monthToSelectFrom, parameters.orgs)
+	}
+	if parameters.isOffline {
+		submissions, err := loadSubmissionsForPeriod(dataDir, monthToSelectFrom, monthToSelectFrom, nil)
+		if err != nil {
+			return fmt.Errorf("The offline mode needs the submissions of the month: %v", err)
+		}
+		cache, err := loadProfileCache(profileCacheFileName)
+		if err != nil {
+			return err
+		}
+		lookupProfile = func(login string) (userProfile, error) {
+			return lookupOfflineUserProfile(login, cache), nil
+		}
+		fetchPRs = func(submittersName string, profile userProfile, submittersPRs string) (error, HonoredContributorData) {
+			return getSubmittersPRfromSubmissions(submittersName, profile, submittersPRs, monthToSelectFrom, parameters.orgs, submissions)
+		}
+	}
+	honoredContributors, err := selectHonoredContributors(candidates, parameters, selectionContext, lookupProfile, fetchPRs)
+	if err != nil {
+		return err
 	}
 
-	// load the file in memory
-	records, err := r.ReadAll()
+	// format the output with the gathered data
+	runDate := getCurrentTimeAsTimeStamp("")
+	output, err := formatHonoredContributors(honoredContributors, runDate, parameters.outputFormat)
 	if err != nil {
-		return fmt.Errorf("Unexpected error loading \""+inputFileName+"\"\n", err)
+		return err
 	}
 
-	if len(records) < 1 {
-		return fmt.Errorf("Error: No data available after the header\n")
+	// Creates, overwrites the output file (no append and with no header generation)
+	if err := writeOutputFile(honorOutputFileName, true, "", []string{strings.TrimSuffix(output, "\n")}); err != nil {
+		return err
 	}
-	if isVerbose {
-		fmt.Println("  - At least one Submitter data available")
+
+	if parameters.announcementFileName != "" {
+		var announcements []string
+		for _, contributorData := range honoredContributors {
+			announcements = append(announcements, strings.TrimSuffix(formatHonorAnnouncement(contributorData), "\n"))
+		}
+		// the month is only recorded in the history once everything was written
+		if err := writeOutputFile(parameters.announcementFileName, true, "", announcements); err != nil {
+			return err
+		}
+	}
+
+	// keep track of the selection
+	var historyLines []string
+	for _, contributorData := range honoredContributors {
+		historyLines = append(historyLines, formatHonoredContributorCSVline(contributorData, runDate))
 	}
+	return appendToHonorHistory(historyFileName, historyLines)
+}
 
-	// pick a data line randomly
-	nbrOfRecordsLoaded := len(records) - 1
+// A set of candidates among which contributors are drawn (all of them or the ones of an organization)
+type honorCandidateGroup struct {
+	org        string
+	candidates []honorCandidate
+}
 
-	randomRecordNumber := rand.IntN(nbrOfRecordsLoaded)
-	submittersName := records[randomRecordNumber][0]
-	submittersPRs := records[randomRecordNumber][1]
-	// fmt.Printf("[%d] - %s - %s PRs\n", randomRecordNumber, records[randomRecordNumber][0], records[randomRecordNumber][1])
-	if isVerbose {
-		fmt.Printf("  - Picked record %d : %s - %s PRs\n", randomRecordNumber, submittersName, submittersPRs)
+// Draws the distinct contributors to honor: "count" of them, or "count" per organization.
+// Each draw uses its own seed (the selection seed plus the number of previous draws).
+func selectHonoredContributors(candidates []honorCandidate, parameters honorSelectionParameters, selectionContext honorSelectionContext,
+	lookupProfile func(login string) (userProfile, error),
+	fetchPRs func(submittersName string, profile userProfile, submittersPRs string) (error, HonoredContributorData)) ([]HonoredContributorData, error) {
+
+	groups := []honorCandidateGroup{{candidates: candidates}}
+	if parameters.isPerOrg {
+		groups = groupCandidatesByOrg(candidates, parameters.orgs)
 	}
 
-	// make a GitHub query to retrieve the contributors information (URL, avatar) and PRs
-	if isVerbose {
-		fmt.Printf("Fetching data from GitHub")
+	var honoredContributors []HonoredContributorData
+	var honoredUsers []string
+	for _, group := range groups {
+		eligible := filterOutCandidates(group.candidates, honoredUsers)
+		for i := 0; i < parameters.count; i++ {
+			drawParameters := parameters
+			drawParameters.seed = parameters.seed + uint64(len(honoredContributors))
+
+			contributorData, err := selectHonoredContributor(eligible, drawParameters, selectionContext, lookupProfile, fetchPRs)
+			if errors.Is(err, errNoEligibleContributor) && len(honoredContributors) > 0 {
+				fmt.Fprintf(os.Stderr, "Warning: not enough eligible contributors%s (%d requested)\n", forOrg(group.org), parameters.count)
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			contributorData.seed = strconv.FormatUint(parameters.seed, 10)
+			contributorData.rank = strconv.Itoa(len(honoredContributors) + 1)
+			contributorData.reason += forOrg(group.org)
+			honoredContributors = append(honoredContributors, contributorData)
+
+			honoredUsers = append(honoredUsers, contributorData.handle)
+			eligible = filterOutCandidates(eligible, []string{contributorData.handle})
+		}
 	}
 
-	var contributorData HonoredContributorData
-	if err, contributorData = getSubmittersPRfromGH(submittersName, submittersPRs, monthToSelectFrom); err != nil {
-		return err
+	if len(honoredContributors) == 0 {
+		return nil, errNoEligibleContributor
 	}
+	return honoredContributors, nil
+}
 
-	// format the output with the gathered data
-	var honorCSVlist []string
-	workBuffer1 := generateHonoredContributorDataCSVheader()
-	workBuffer2 := fmt.Sprintf("\"%s\", ", getCurrentTimeAsTimeStamp("")) + generateHonoredContributorDataAsCSV(contributorData)
-	honorCSVlist = append(honorCSVlist, workBuffer1, workBuffer2)
+// Splits the candidates per organization (based on the repositories they contributed to).
+// A candidate contributing to several organizations is part of several groups.
+func groupCandidatesByOrg(candidates []honorCandidate, orgs []string) []honorCandidateGroup {
+	var groups []honorCandidateGroup
+	for _, org := range orgs {
+		group := honorCandidateGroup{org: org}
+		for _, candidate := range candidates {
+			for _, repository := range candidate.repositories {
+				if strings.HasPrefix(strings.ToLower(repository), strings.ToLower(org)+"/") {
+					group.candidates = append(group.candidates, candidate)
+					break
+				}
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
 
-	// output the file
+// Returns the " for <org>" suffix of the messages and reasons (empty if there is no organization)
+func forOrg(org string) string {
+	if org == "" {
+		return ""
+	}
+	return " for " + org
+}
 
-	// Creates, overwrites the output file (no append and with no header generation)
-	out, _ := openOutputCSV(honorOutputFileName, false, true)
-	defer out.Close()
+// Returns the candidates that are not in the list of users (excluded or honored recently)
+func filterOutCandidates(candidates []honorCandidate, users []string) []honorCandidate {
+	var eligible []honorCandidate
+	for _, candidate := range candidates {
+		if !isExcludedAuthor(users, candidate.user) {
+			eligible = append(eligible, candidate)
+		}
+	}
+	return eligible
+}
+
+// Returned when all the candidates were discarded
+var errNoEligibleContributor = errors.New("No eligible contributor left (bots, not user accounts or PR number mismatches)")
+
+// Picks a candidate and checks their GitHub profile. Bots, organizations and deleted accounts
+// can't be honored: the candidate is then discarded and another one is drawn.
+// The returned selection context is the one of the draw that picked the candidate.
+func drawHonorCandidate(candidates []honorCandidate, parameters honorSelectionParameters, selectionContext honorSelectionContext,
+	lookupProfile func(login string) (userProfile, error)) (honorCandidate, userProfile, honorSelectionContext, error) {
+	for len(candidates) > 0 {
+		selected, err := selectHonorCandidate(candidates, parameters.strategy, selectionContext, parameters.seed)
+		if err != nil {
+			return honorCandidate{}, userProfile{}, selectionContext, err
+		}
+		candidate := candidates[selected]
+
+		profile, err := lookupProfile(candidate.user)
+		if err != nil {
+			return honorCandidate{}, userProfile{}, selectionContext, fmt.Errorf("Error retrieving user profile: %v\n", err)
+		}
+		if isHonorableAccount(profile) {
+			return candidate, profile, selectionContext.forCandidates(candidates), nil
+		}
 
-	writeCSVtoFile(out, false, true, "", honorCSVlist)
-	out.Close()
+		fmt.Fprintf(os.Stderr, "Skipping \"%s\" (%s account): drawing again\n", candidate.user, profile.accountType)
+		candidates = append(candidates[:selected:selected], candidates[selected+1:]...)
+	}
+	return honorCandidate{}, userProfile{}, selectionContext, errNoEligibleContributor
+}
+
+// Draws a contributor and retrieves their PRs from GitHub. When the number of PRs found is not the
+// expected one, the mismatch policy tells whether the contributor is kept or another one is drawn.
+func selectHonoredContributor(candidates []honorCandidate, parameters honorSelectionParameters, selectionContext honorSelectionContext,
+	lookupProfile func(login string) (userProfile, error),
+	fetchPRs func(submittersName string, profile userProfile, submittersPRs string) (error, HonoredContributorData)) (HonoredContributorData, error) {
+	for {
+		selected, profile, drawContext, err := drawHonorCandidate(candidates, parameters, selectionContext, lookupProfile)
+		if err != nil {
+			return HonoredContributorData{}, err
+		}
 
+		err, contributorData := fetchPRs(selected.user, profile, strconv.Itoa(selected.prs))
+		if err != nil {
+			return contributorData, err
+		}
+		if reconcilePRcount(contributorData, parameters.onMismatch) {
+			contributorData.reason = honorStrategies[parameters.strategy].reason(selected, drawContext)
+			return contributorData, nil
+		}
+
+		candidates = filterOutCandidates(candidates, []string{selected.user})
+	}
+}
+
+// Compares the number of PRs found on GitHub with the expected one.
+// Returns false if the contributor must be discarded (mismatch with the "redraw" policy).
+func reconcilePRcount(contributorData HonoredContributorData, onMismatch string) bool {
+	if contributorData.totalPRs_expected == contributorData.totalPRs_found {
+		return true
+	}
+
+	switch onMismatch {
+	case mismatchRedraw:
+		fmt.Fprintf(os.Stderr, "Expected PR number of \"%s\" does not match query's PR number (%s vs. %s): drawing again\n",
+			contributorData.handle, contributorData.totalPRs_expected, contributorData.totalPRs_found)
+		return false
+	case mismatchAccept:
+		if isVerbose {
+			fmt.Fprintf(os.Stderr, "  - PR number mismatch accepted (%s vs. %s)\n", contributorData.totalPRs_expected, contributorData.totalPRs_found)
+		}
+		return true
+	default:
+		fmt.Fprintf(os.Stderr, "Warning: expected PR number of \"%s\" does not match query's PR number (%s vs. %s)\n",
+			contributorData.handle, contributorData.totalPRs_expected, contributorData.totalPRs_found)
+		return true
+	}
+}
+
+// Checks the mismatch policy (see "--on_mismatch")
+func validateMismatchPolicy(onMismatch string) error {
+	switch onMismatch {
+	case mismatchWarn, mismatchRedraw, mismatchAccept:
+		return nil
+	}
+	return fmt.Errorf("Unsupported mismatch policy \"%s\" (should be one of %s)", onMismatch, prettyPrintStringList([]string{mismatchWarn, mismatchRedraw, mismatchAccept}))
+}
+
+// Checks that at least one organization is given and that they are all valid
+func validateHonorOrgs(orgs []string) error {
+	if len(orgs) == 0 {
+		return fmt.Errorf("At least one organization is required (see \"--orgs\")")
+	}
+	for _, org := range orgs {
+		if !isValidOrgFormat(org) {
+			return fmt.Errorf("\"%s\" is not a valid organization name", org)
+		}
+	}
 	return nil
 }
 
-var uniqueRepoSet = make(map[string]bool)
-var uniqueRepoSlice = []string{}
+// Builds the GitHub search query of the PRs created by the user (with any of their logins, see "--aliases")
+// in the organizations during the period
+func buildHonorPRsearchQuery(orgs []string, submittersName string, startDate string, endDate string) string {
+	var query strings.Builder
+	for _, org := range orgs {
+		query.WriteString("org:" + org + " ")
+	}
+	query.WriteString("is:pr ")
+	for _, login := range userAliases.expandLogins([]string{submittersName}) {
+		query.WriteString("author:" + login + " ")
+	}
+	query.WriteString(fmt.Sprintf("created:%s..%s", startDate, endDate))
+	return query.String()
+}
+
+// Returns true if the profile is the one of an actual person (not a bot, an organization or a deleted account)
+func isHonorableAccount(profile userProfile) bool {
+	return profile.accountType == accountUser && !botLogin_regexp.MatchString(profile.login) && !isUserBot(profile.url)
+}
+
+// Computes the default seed of the selection: a hash of the month, so that the selection of a month is reproducible
+func computeDefaultHonorSeed(month string) uint64 {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(month))
+	return hash.Sum64()
+}
+
+// Picks randomly one of the records (all of them are eligible). The same seed always gives the same pick.
+func selectRandomRecord(nbrOfRecords int, seed uint64) int {
+	random := rand.New(rand.NewPCG(seed, seed))
+	return random.IntN(nbrOfRecords)
+}
+
+// Initializes the data of an honored contributor (the user's information comes from their profile)
+func newHonoredContributorData(submittersName string, profile userProfile, submittersPRs string, monthToSelectFrom string) HonoredContributorData {
+	return HonoredContributorData{
+		handle:            submittersName,
+		fullName:          profile.name,
+		authorURL:         profile.url,
+		authorAvatarUrl:   profile.avatarURL,
+		authorCompany:     profile.company,
+		month:             monthToSelectFrom,
+		totalPRs_expected: submittersPRs,
+	}
+}
+
+// Computes the repositories of the contributor (with their PR count) from their PRs
+func (d *HonoredContributorData) setRepositories() {
+	d.repositoryPRs = countPRsPerRepository(d.pullRequests)
+	// the repositories are stored as a string with items separated by spaces
+	d.repositories = stringifySlice(repositoryNames(d.repositoryPRs))
+}
+
+// Counts the PRs per repository. The repositories are in the order of their first PR in the list.
+func countPRsPerRepository(pullRequests []honoredPullRequest) []honoredRepository {
+	var repositories []honoredRepository
+	indexByName := make(map[string]int)
+	for _, pr := range pullRequests {
+		i, ok := indexByName[pr.repository]
+		if !ok {
+			i = len(repositories)
+			indexByName[pr.repository] = i
+			repositories = append(repositories, honoredRepository{name: pr.repository})
+		}
+		repositories[i].prs++
+	}
+	return repositories
+}
 
-// Adds an item to the slice only if it is not there yet. See https://stackoverflow.com/questions/33207197/how-can-i-create-an-array-that-contains-unique-strings
-func addUniqueItem(s string) {
-	if uniqueRepoSet[s] {
-		return // Already in the map
+// Returns the names of the repositories
+func repositoryNames(repositories []honoredRepository) []string {
+	var names []string
+	for _, repository := range repositories {
+		names = append(names, repository.name)
 	}
-	uniqueRepoSlice = append(uniqueRepoSlice, s)
-	uniqueRepoSet[s] = true
+	return names
 }
 
 /*****
@@ -241,7 +697,7 @@ func addUniqueItem(s string) {
 //******************************
 
 // Gets all the PRs in the given month for the submitters
-func getSubmittersPRfromGH(submittersName string, submittersPRs string, monthToSelectFrom string) (error, HonoredContributorData) {
+func getSubmittersPRfromGH(submittersName string, profile userProfile, submittersPRs string, monthToSelectFrom string, orgs []string) (error, HonoredContributorData) {
 
 	// Setup the GH query client
 	ghToken := loadGitHubToken(ghTokenVar)
@@ -251,34 +707,7 @@ func getSubmittersPRfromGH(submittersName string, submittersPRs string, monthToS
 	httpClient := oauth2.NewClient(context.Background(), src)
 	client := githubv4.NewClient(httpClient)
 
-	var contributorData HonoredContributorData
-	contributorData.handle = submittersName
-	contributorData.totalPRs_expected = submittersPRs
-	contributorData.month = monthToSelectFrom
-
-	// Setup the query to retrieve the user's information
-	var userQuery struct {
-		User struct {
-			Login     string
-			Name      string
-			Company   string
-			AvatarUrl string
-			Url       string
-		} `graphql:"user(login: $submitter)"`
-	}
-	userVariables := map[string]interface{}{
-		"submitter": githubv4.String(submittersName),
-	}
-	if err := client.Query(context.Background(), &userQuery, userVariables); err != nil {
-		return fmt.Errorf("Error performing user query: %v\n", err), contributorData
-	}
-
-	// Load the data form the returned json file
-	contributorData.handle = submittersName
-	contributorData.fullName = userQuery.User.Name
-	contributorData.authorURL = userQuery.User.Url
-	contributorData.authorAvatarUrl = userQuery.User.AvatarUrl
-	contributorData.authorCompany = userQuery.User.Company
+	contributorData := newHonoredContributorData(submittersName, profile, submittersPRs, monthToSelectFrom)
 
 	// Setup the GH call to retrieve all the contributions
 	startDate, endDate := getStartAndEndOfMonth(monthToSelectFrom)
@@ -303,47 +732,50 @@ func getSubmittersPRfromGH(submittersName string, submittersPRs string, monthToS
 					} `graphql:"... on PullRequest"`
 				}
 			}
-		} `graphql:"search(first: $count, query: $searchQuery, type: ISSUE)"`
+			PageInfo struct {
+				EndCursor   githubv4.String
+				HasNextPage bool
+			}
+		} `graphql:"search(first: $count, after: $pullRequestCursor, query: $searchQuery, type: ISSUE)"`
 	}
 
 	variables := map[string]interface{}{
-		"searchQuery": githubv4.String(
-			fmt.Sprintf(`org:%s org:%s org:%s is:pr author:%s created:%s..%s`,
-				githubv4.String("jenkinsci"),
-				githubv4.String("jenkins-infra"),
-				githubv4.String("jenkins-docs"),
-				githubv4.String(submittersName),
-				githubv4.String(startDate),
-				githubv4.String(endDate),
-			),
-		),
-		"count": githubv4.Int(100),
+		"searchQuery":       githubv4.String(buildHonorPRsearchQuery(orgs, submittersName, startDate, endDate)),
+		"count":             githubv4.Int(100),
+		"pullRequestCursor": (*githubv4.String)(nil), // Null after argument to get first page.
 	}
 
-	if err := client.Query(context.Background(), &prQuery3, variables); err != nil {
-		return fmt.Errorf("Error performing PR query: %v\n", err), contributorData
-	}
+	// Loop through all the result pages
+	for {
+		if err := client.Query(context.Background(), &prQuery3, variables); err != nil {
+			return fmt.Errorf("Error performing PR query: %v\n", err), contributorData
+		}
 
-	totalPRs := prQuery3.Search.IssueCount
-	contributorData.totalPRs_found = strconv.Itoa(totalPRs)
-	if contributorData.totalPRs_expected != contributorData.totalPRs_found {
-		return fmt.Errorf("Expected PR number does not match query's PR number. (%s vs. %s)", contributorData.totalPRs_expected, contributorData.totalPRs_found), contributorData
-	}
+		for _, singlePr := range prQuery3.Search.Edges {
+			if !userAliases.isSameIdentity(singlePr.Node.PullRequest.Author.Login, submittersName) {
+				return fmt.Errorf("Unexpected error: PR author does not match requested GH userName (%s vs. %s)", singlePr.Node.PullRequest.Author.Login, submittersName), contributorData
+			}
+			repositoryName := singlePr.Node.PullRequest.Repository.Owner.Login + "/" + singlePr.Node.PullRequest.Repository.Name
+			contributorData.pullRequests = append(contributorData.pullRequests, honoredPullRequest{
+				url:        singlePr.Node.PullRequest.Url,
+				title:      singlePr.Node.PullRequest.Title,
+				repository: repositoryName,
+			})
+		}
 
-	for _, singlePr := range prQuery3.Search.Edges {
-		if singlePr.Node.PullRequest.Author.Login != submittersName {
-			return fmt.Errorf("Unexpected error: PR author does not match requested GH userName (%s vs. %s)", singlePr.Node.PullRequest.Author.Login, submittersName), contributorData
+		if !prQuery3.Search.PageInfo.HasNextPage {
+			break
 		}
-		repositoryName := singlePr.Node.PullRequest.Repository.Owner.Login + "/" + singlePr.Node.PullRequest.Repository.Name
-		addUniqueItem(repositoryName)
+		variables["pullRequestCursor"] = githubv4.NewString(prQuery3.Search.PageInfo.EndCursor)
 	}
 
-	// takes the slice and generates a string with items separated by spaces
-	contributorData.repositories = stringifySlice(uniqueRepoSlice)
+	totalPRs := prQuery3.Search.IssueCount
+	contributorData.totalPRs_found = strconv.Itoa(totalPRs)
+	contributorData.setRepositories()
 
 	if isVerbose {
-		fmt.Print("\n\n")
-		fmt.Println(prettyPrint_HonoredContributorData(contributorData))
+		fmt.Fprint(os.Stderr, "\n\n")
+		fmt.Fprintln(os.Stderr, prettyPrint_HonoredContributorData(contributorData))
 	}
 
 	return nil, contributorData
@@ -355,7 +787,10 @@ func prettyPrint_HonoredContributorData(data HonoredContributorData) string {
 	strBuffer.WriteString(fmt.Sprintf("PRs found:    %s\n", data.totalPRs_found))
 	strBuffer.WriteString(fmt.Sprintf("PRs expected: %s\n", data.totalPRs_expected))
 	strBuffer.WriteString(fmt.Sprintf("Month:        %s\n", data.month))
-	strBuffer.WriteString(fmt.Sprintf("Repositories: %s\n\n", data.repositories))
+	strBuffer.WriteString(fmt.Sprintf("Repositories: %s\n", data.repositories))
+	strBuffer.WriteString(fmt.Sprintf("Seed:         %s\n", data.seed))
+	strBuffer.WriteString(fmt.Sprintf("Rank:         %s\n", data.rank))
+	strBuffer.WriteString(fmt.Sprintf("Reason:       %s\n\n", data.reason))
 	strBuffer.WriteString(fmt.Sprintf("GH handle:    %s\n", data.handle))
 	strBuffer.WriteString(fmt.Sprintf("User name:    %s\n", data.fullName))
 	strBuffer.WriteString(fmt.Sprintf("URL:          %s\n", data.authorURL))
@@ -396,7 +831,7 @@ func getCurrentTimeAsTimeStamp(suppliedTime string) string {
 // Makes it easier to test and to use to generate header
 func generateHonoredContributorDataAsCSV(contributorData HonoredContributorData) string {
 
-	outBuffer := fmt.Sprintf("\"%s\", \"%s\", \"%s\", \"%s\", \"%s\", \"%s\", \"%s\", \"%s\"",
+	return formatHonorCSVrecord([]string{
 		contributorData.month,
 		contributorData.handle,
 		contributorData.fullName,
@@ -405,9 +840,19 @@ func generateHonoredContributorDataAsCSV(contributorData HonoredContributorData)
 		contributorData.authorAvatarUrl,
 		contributorData.totalPRs_found,
 		contributorData.repositories,
-	)
+		contributorData.seed,
+		contributorData.rank,
+		contributorData.reason,
+	})
+}
 
-	return outBuffer
+// Formats a record the way the honor files are written: every field is quoted (quotes are doubled)
+func formatHonorCSVrecord(record []string) string {
+	var fields []string
+	for _, field := range record {
+		fields = append(fields, quoteCSVfield(field))
+	}
+	return strings.Join(fields, ", ")
 }
 
 // Generates the data part of the CSV (without time stamp).
@@ -423,6 +868,9 @@ func generateHonoredContributorDataCSVheader() string {
 		totalPRs_found:    "NBR_PR",
 		totalPRs_expected: "",
 		repositories:      "REPOSITORIES",
+		seed:              "SEED",
+		rank:              "RANK",
+		reason:            "REASON",
 	}
 
 	shortHeader := generateHonoredContributorDataAsCSV(headerData)
@@ -23,12 +23,27 @@ package cmd
 
 import (
 	"bytes"
+	"fmt"
 	"path/filepath"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
+// Returns the selection parameters of the command's defaults
+func newTestHonorParameters(seed uint64) honorSelectionParameters {
+	return honorSelectionParameters{
+		seed:           seed,
+		cooldownMonths: defaultHonorCooldownMonths,
+		strategy:       strategyUniform,
+		orgs:           defaultHonorOrgs,
+		onMismatch:     mismatchWarn,
+		outputFormat:   formatCSV,
+		count:          1,
+	}
+}
+
 func Test_performHonorContributorSelection_params(t *testing.T) {
 	type args struct {
 		dataDir           string
@@ -65,17 +80,25 @@ func Test_performHonorContributorSelection_params(t *testing.T) {
 			true,
 		},
 		{
-			"invalid header in input file",
+			"extra column in input file is tolerated",
 			args{
 				dataDir:           "../test-data",
 				monthToSelectFrom: "2024-03",
 			},
+			false,
+		},
+		{
+			"invalid header in input file",
+			args{
+				dataDir:           "../test-data",
+				monthToSelectFrom: "2024-02",
+			},
 			true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := performHonorContributorSelection(tt.args.dataDir, tt.args.outputFileName, tt.args.monthToSelectFrom); (err != nil) != tt.wantErr {
+			if err := performHonorContributorSelection(tt.args.dataDir, tt.args.outputFileName, tt.args.monthToSelectFrom, newTestHonorParameters(computeDefaultHonorSeed(tt.args.monthToSelectFrom))); (err != nil) != tt.wantErr {
 				t.Errorf("performHonorContributorSelection() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -193,9 +216,12 @@ func Test_generateHonoredContributorDataAsCSV(t *testing.T) {
 					totalPRs_found:    "PR_found",
 					totalPRs_expected: "PR_expected",
 					repositories:      "repositories",
+					seed:              "42",
+					rank:              "1",
+					reason:            "random draw among 12 contributors",
 				},
 			},
-			"\"a_month\", \"GH_handle\", \"author_fullName\", \"a_company\", \"author_url\", \"author_avatar\", \"PR_found\", \"repositories\"",
+			"\"a_month\", \"GH_handle\", \"author_fullName\", \"a_company\", \"author_url\", \"author_avatar\", \"PR_found\", \"repositories\", \"42\", \"1\", \"random draw among 12 contributors\"",
 		},
 		{
 			"with empty fields",
@@ -210,9 +236,10 @@ func Test_generateHonoredContributorDataAsCSV(t *testing.T) {
 					totalPRs_found:    "PR_found",
 					totalPRs_expected: "PR_expected",
 					repositories:      "repositories",
+					seed:              "42",
 				},
 			},
-			"\"a_month\", \"GH_handle\", \"\", \"\", \"author_url\", \"author_avatar\", \"PR_found\", \"repositories\"",
+			"\"a_month\", \"GH_handle\", \"\", \"\", \"author_url\", \"author_avatar\", \"PR_found\", \"repositories\", \"42\", \"\", \"\"",
 		},
 	}
 	for _, tt := range tests {
@@ -223,3 +250,311 @@ func Test_generateHonoredContributorDataAsCSV(t *testing.T) {
 		})
 	}
 }
+
+func Test_computeDefaultHonorSeed(t *testing.T) {
+	assert.Equal(t, computeDefaultHonorSeed("2024-04"), computeDefaultHonorSeed("2024-04"), "The default seed should be stable")
+	assert.NotEqual(t, computeDefaultHonorSeed("2024-04"), computeDefaultHonorSeed("2024-05"))
+}
+
+func Test_selectRandomRecord(t *testing.T) {
+	assert.Equal(t, selectRandomRecord(185, 42), selectRandomRecord(185, 42), "The same seed should give the same pick")
+	assert.Equal(t, 0, selectRandomRecord(1, 42), "A single record should be selectable")
+
+	// Every record, including the last one, is eligible
+	picked := make(map[int]bool)
+	for seed := uint64(0); seed < 200; seed++ {
+		pick := selectRandomRecord(3, seed)
+		assert.True(t, pick >= 0 && pick < 3, "Pick out of range")
+		picked[pick] = true
+	}
+	assert.Equal(t, 3, len(picked), "All records should be picked at least once")
+}
+
+func Test_filterOutCandidates(t *testing.T) {
+	candidates := []honorCandidate{{user: "basil", prs: 69}, {user: "gounthar", prs: 40}, {user: "lemeurherve", prs: 33}}
+
+	assert.Equal(t, []honorCandidate{{user: "lemeurherve", prs: 33}}, filterOutCandidates(candidates, []string{"Basil", "gounthar"}))
+	assert.Equal(t, candidates, filterOutCandidates(candidates, nil))
+}
+
+func Test_filterOutCandidates_withAliases(t *testing.T) {
+	var err error
+	userAliases, err = loadIdentityAliases("../test-data/aliases.txt")
+	assert.NoError(t, err)
+	defer func() { userAliases = identityAliases{} }()
+
+	candidates := []honorCandidate{{user: "alice", prs: 3}, {user: "bob", prs: 2}}
+	assert.Equal(t, []honorCandidate{{user: "bob", prs: 2}}, filterOutCandidates(candidates, []string{"alice-old"}))
+}
+
+func Test_isHonorableAccount(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile userProfile
+		want    bool
+	}{
+		{"user", userProfile{login: "alice", url: "https://github.com/alice", accountType: accountUser}, true},
+		{"bot", userProfile{login: "dependabot[bot]", accountType: accountBot}, false},
+		{"organization", userProfile{login: "jenkinsci", accountType: accountOrganization}, false},
+		{"deleted account", userProfile{login: "ghost", accountType: accountUnknown}, false},
+		{"bot-like login", userProfile{login: "renovate[bot]", accountType: accountUser}, false},
+		{"application URL", userProfile{login: "jenkins-ci", url: "https://github.com/apps/jenkins-ci", accountType: accountUser}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isHonorableAccount(tt.profile))
+		})
+	}
+}
+
+func Test_drawHonorCandidate(t *testing.T) {
+	profiles := map[string]userProfile{
+		"alice":           {login: "alice", accountType: accountUser},
+		"dependabot[bot]": {login: "dependabot[bot]", accountType: accountBot},
+		"jenkinsci":       {login: "jenkinsci", accountType: accountOrganization},
+	}
+	var lookedUp []string
+	lookupProfile := func(login string) (userProfile, error) {
+		lookedUp = append(lookedUp, login)
+		profile, ok := profiles[login]
+		if !ok {
+			return userProfile{}, fmt.Errorf("unknown user %s", login)
+		}
+		return profile, nil
+	}
+	parameters := honorSelectionParameters{strategy: strategyUniform, seed: 42}
+
+	// whatever the order of the draws, the only user account is the one picked
+	candidates := []honorCandidate{{user: "dependabot[bot]", prs: 5}, {user: "alice", prs: 1}, {user: "jenkinsci", prs: 2}}
+	selected, profile, drawContext, err := drawHonorCandidate(candidates, parameters, honorSelectionContext{}, lookupProfile)
+	assert.NoError(t, err)
+	assert.Equal(t, honorCandidate{user: "alice", prs: 1}, selected)
+	assert.Equal(t, "alice", profile.login)
+	assert.Equal(t, "alice", lookedUp[len(lookedUp)-1])
+	assert.Equal(t, len(candidates)-len(lookedUp)+1, drawContext.nbrOfCandidates, "The discarded accounts are not part of the last draw")
+	assert.Equal(t, []honorCandidate{{user: "dependabot[bot]", prs: 5}, {user: "alice", prs: 1}, {user: "jenkinsci", prs: 2}}, candidates, "The candidate list should not be modified")
+
+	_, _, _, err = drawHonorCandidate([]honorCandidate{{user: "dependabot[bot]"}, {user: "jenkinsci"}}, parameters, honorSelectionContext{}, lookupProfile)
+	assert.ErrorContains(t, err, "No eligible contributor left")
+
+	_, _, _, err = drawHonorCandidate([]honorCandidate{{user: "unknown"}}, parameters, honorSelectionContext{}, lookupProfile)
+	assert.ErrorContains(t, err, "Error retrieving user profile")
+}
+
+func Test_performHonorContributorSelection_allExcluded(t *testing.T) {
+	parameters := newTestHonorParameters(42)
+	parameters.excludedUsers = []string{"bob", "dave", "alice"}
+	err := performHonorContributorSelection("../test-data/monthly", "", "2024-02", parameters)
+	assert.ErrorContains(t, err, "--excludeFile")
+}
+
+func Test_honorCommand_invalidExcludeFile(t *testing.T) {
+	defer func() { honorExcludeFileName = "" }()
+
+	actual := new(bytes.Buffer)
+	rootCmd.SetOut(actual)
+	rootCmd.SetErr(actual)
+	rootCmd.SetArgs([]string{"honor", "2024-02", "--data_dir=../test-data/monthly", "--excludeFile=../test-data/inexistent.txt"})
+
+	error := rootCmd.Execute()
+
+	assert.ErrorContains(t, error, "invalid excluded user list")
+}
+
+func Test_validateHonorOrgs(t *testing.T) {
+	assert.NoError(t, validateHonorOrgs(defaultHonorOrgs))
+	assert.NoError(t, validateHonorOrgs([]string{"jenkinsci"}))
+	assert.Error(t, validateHonorOrgs(nil))
+	assert.Error(t, validateHonorOrgs([]string{"jenkinsci", "junk/org"}))
+}
+
+func Test_validateMismatchPolicy(t *testing.T) {
+	for _, policy := range []string{mismatchWarn, mismatchRedraw, mismatchAccept} {
+		assert.NoError(t, validateMismatchPolicy(policy))
+	}
+	assert.Error(t, validateMismatchPolicy("fail"))
+}
+
+func Test_buildHonorPRsearchQuery(t *testing.T) {
+	assert.Equal(t, "org:jenkinsci org:jenkins-infra org:jenkins-docs is:pr author:basil created:2024-04-01..2024-04-30",
+		buildHonorPRsearchQuery(defaultHonorOrgs, "basil", "2024-04-01", "2024-04-30"))
+	assert.Equal(t, "org:jenkinsci is:pr author:basil created:2024-04-01..2024-04-30",
+		buildHonorPRsearchQuery([]string{"jenkinsci"}, "basil", "2024-04-01", "2024-04-30"))
+}
+
+func Test_buildHonorPRsearchQuery_withAliases(t *testing.T) {
+	var err error
+	userAliases, err = loadIdentityAliases("../test-data/aliases.txt")
+	assert.NoError(t, err)
+	defer func() { userAliases = identityAliases{} }()
+
+	assert.Equal(t, "org:jenkinsci is:pr author:alice author:alice-old author:alice-work created:2024-04-01..2024-04-30",
+		buildHonorPRsearchQuery([]string{"jenkinsci"}, "alice", "2024-04-01", "2024-04-30"))
+}
+
+func Test_reconcilePRcount(t *testing.T) {
+	matching := HonoredContributorData{handle: "basil", totalPRs_expected: "3", totalPRs_found: "3"}
+	mismatching := HonoredContributorData{handle: "basil", totalPRs_expected: "3", totalPRs_found: "4"}
+
+	tests := []struct {
+		name       string
+		data       HonoredContributorData
+		onMismatch string
+		want       bool
+	}{
+		{"matching whatever the policy", matching, mismatchRedraw, true},
+		{"mismatch with warning", mismatching, mismatchWarn, true},
+		{"mismatch accepted", mismatching, mismatchAccept, true},
+		{"mismatch redrawn", mismatching, mismatchRedraw, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, reconcilePRcount(tt.data, tt.onMismatch))
+		})
+	}
+}
+
+func Test_selectHonoredContributor(t *testing.T) {
+	lookupProfile := func(login string) (userProfile, error) {
+		return userProfile{login: login, accountType: accountUser}, nil
+	}
+	// only alice's PRs match the data
+	actualPRs := map[string]string{"alice": "1", "bob": "7", "dave": "0"}
+	var fetched []string
+	fetchPRs := func(submittersName string, profile userProfile, submittersPRs string) (error, HonoredContributorData) {
+		fetched = append(fetched, submittersName)
+		if submittersName == "error" {
+			return fmt.Errorf("query failed"), HonoredContributorData{}
+		}
+		return nil, HonoredContributorData{handle: submittersName, totalPRs_expected: submittersPRs, totalPRs_found: actualPRs[submittersName]}
+	}
+	candidates := []honorCandidate{{user: "bob", prs: 2}, {user: "alice", prs: 1}, {user: "dave", prs: 1}}
+
+	parameters := honorSelectionParameters{strategy: strategyUniform, seed: 42, onMismatch: mismatchRedraw}
+	got, err := selectHonoredContributor(candidates, parameters, honorSelectionContext{}, lookupProfile, fetchPRs)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", got.handle)
+	assert.Equal(t, "alice", fetched[len(fetched)-1])
+
+	parameters.onMismatch = mismatchWarn
+	fetched = nil
+	got, err = selectHonoredContributor(candidates, parameters, honorSelectionContext{}, lookupProfile, fetchPRs)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(fetched), "No redraw expected")
+	assert.Equal(t, fetched[0], got.handle)
+
+	parameters.onMismatch = mismatchRedraw
+	_, err = selectHonoredContributor([]honorCandidate{{user: "bob", prs: 2}, {user: "dave", prs: 1}}, parameters, honorSelectionContext{}, lookupProfile, fetchPRs)
+	assert.ErrorContains(t, err, "No eligible contributor left")
+
+	_, err = selectHonoredContributor([]honorCandidate{{user: "error", prs: 2}}, parameters, honorSelectionContext{}, lookupProfile, fetchPRs)
+	assert.ErrorContains(t, err, "query failed")
+}
+
+func Test_performHonorContributorSelection_invalidParameters(t *testing.T) {
+	parameters := newTestHonorParameters(42)
+	parameters.orgs = []string{"junk/org"}
+	assert.ErrorContains(t, performHonorContributorSelection("../test-data/monthly", "", "2024-02", parameters), "not a valid organization")
+
+	parameters = newTestHonorParameters(42)
+	parameters.count = 0
+	assert.ErrorContains(t, performHonorContributorSelection("../test-data/monthly", "", "2024-02", parameters), "--count")
+
+	parameters = newTestHonorParameters(42)
+	parameters.onMismatch = "fail"
+	assert.ErrorContains(t, performHonorContributorSelection("../test-data/monthly", "", "2024-02", parameters), "Unsupported mismatch policy")
+}
+
+func Test_groupCandidatesByOrg(t *testing.T) {
+	bob := honorCandidate{user: "bob", repositories: []string{"jenkinsci/jenkins"}}
+	carol := honorCandidate{user: "carol", repositories: []string{"jenkins-infra/helpdesk", "JenkinsCI/git-plugin"}}
+	frank := honorCandidate{user: "frank"}
+
+	assert.Equal(t, []honorCandidateGroup{
+		{org: "jenkinsci", candidates: []honorCandidate{bob, carol}},
+		{org: "jenkins-infra", candidates: []honorCandidate{carol}},
+		{org: "jenkins-docs"},
+	}, groupCandidatesByOrg([]honorCandidate{bob, carol, frank}, defaultHonorOrgs))
+}
+
+func Test_selectHonoredContributors(t *testing.T) {
+	lookupProfile := func(login string) (userProfile, error) {
+		accountType := accountUser
+		if login == "renovate[bot]" {
+			accountType = accountBot
+		}
+		return userProfile{login: login, accountType: accountType}, nil
+	}
+	fetchPRs := func(submittersName string, profile userProfile, submittersPRs string) (error, HonoredContributorData) {
+		return nil, HonoredContributorData{handle: submittersName, totalPRs_expected: submittersPRs, totalPRs_found: submittersPRs}
+	}
+	candidates := []honorCandidate{
+		{user: "bob", prs: 2, repositories: []string{"jenkinsci/jenkins"}},
+		{user: "carol", prs: 1, repositories: []string{"jenkins-infra/helpdesk"}},
+		{user: "renovate[bot]", prs: 9, repositories: []string{"jenkins-infra/helpdesk"}},
+		{user: "dave", prs: 1, repositories: []string{"jenkinsci/git-plugin"}},
+	}
+	selectionContext := honorSelectionContext{nbrOfCandidates: 4}
+
+	parameters := newTestHonorParameters(42)
+	parameters.count = 3
+	got, err := selectHonoredContributors(candidates, parameters, selectionContext, lookupProfile, fetchPRs)
+	assert.NoError(t, err)
+	var users []string
+	var reasons []string
+	for i, contributorData := range got {
+		users = append(users, contributorData.handle)
+		reasons = append(reasons, contributorData.reason)
+		assert.Equal(t, strconv.Itoa(i+1), contributorData.rank)
+		assert.Equal(t, "42", contributorData.seed)
+	}
+	// the contributors already drawn and the discarded bot are not counted
+	assert.Equal(t, []string{"random draw among 3 contributors", "random draw among 2 contributors", "random draw among 2 contributors"}, reasons)
+	assert.ElementsMatch(t, []string{"bob", "carol", "dave"}, users, "Distinct users (and no bot) expected")
+
+	again, _ := selectHonoredContributors(candidates, parameters, selectionContext, lookupProfile, fetchPRs)
+	assert.Equal(t, got, again, "The selection should be reproducible")
+
+	// not enough candidates: the available ones are honored
+	parameters.count = 10
+	got, err = selectHonoredContributors(candidates, parameters, selectionContext, lookupProfile, fetchPRs)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(got))
+
+	// one per organization: the bot is skipped and nobody contributed to jenkins-docs
+	parameters.count = 1
+	parameters.isPerOrg = true
+	got, err = selectHonoredContributors(candidates, parameters, selectionContext, lookupProfile, fetchPRs)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(got))
+	assert.Contains(t, []string{"bob", "dave"}, got[0].handle)
+	assert.Equal(t, "random draw among 2 contributors for jenkinsci", got[0].reason)
+	assert.Equal(t, "carol", got[1].handle)
+	assert.Equal(t, "random draw among 1 contributors for jenkins-infra", got[1].reason, "The bot is discarded")
+
+	// a contributor is honored only once, even if they contributed to several organizations
+	both := []honorCandidate{{user: "erin", prs: 2, repositories: []string{"jenkinsci/jenkins", "jenkins-infra/helpdesk"}}}
+	got, err = selectHonoredContributors(both, parameters, selectionContext, lookupProfile, fetchPRs)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(got))
+
+	_, err = selectHonoredContributors([]honorCandidate{{user: "renovate[bot]"}}, newTestHonorParameters(42), selectionContext, lookupProfile, fetchPRs)
+	assert.ErrorIs(t, err, errNoEligibleContributor)
+}
+
+func Test_countPRsPerRepository(t *testing.T) {
+	pullRequests := []honoredPullRequest{
+		{url: "https://github.com/jenkinsci/jenkins/pull/9001", repository: "jenkinsci/jenkins"},
+		{url: "https://github.com/jenkins-infra/helpdesk/pull/10", repository: "jenkins-infra/helpdesk"},
+		{url: "https://github.com/jenkinsci/jenkins/pull/9002", repository: "jenkinsci/jenkins"},
+	}
+
+	got := countPRsPerRepository(pullRequests)
+	assert.Equal(t, []honoredRepository{{name: "jenkinsci/jenkins", prs: 2}, {name: "jenkins-infra/helpdesk", prs: 1}}, got)
+	assert.Equal(t, []string{"jenkinsci/jenkins", "jenkins-infra/helpdesk"}, repositoryNames(got))
+
+	// each contributor gets their own repositories
+	other := countPRsPerRepository([]honoredPullRequest{{repository: "jenkinsci/git-plugin"}})
+	assert.Equal(t, []honoredRepository{{name: "jenkinsci/git-plugin", prs: 1}}, other)
+	assert.Empty(t, countPRsPerRepository(nil))
+}
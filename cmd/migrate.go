@@ -0,0 +1,140 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var migrateOutput string
+var migrate_requireBackup bool
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate <filename>",
+	Short: "Upgrades a data file to the current schema",
+	Long: `This command upgrades a data file generated by an older version of the tool
+(or by external scripts) to the current schema of its kind.
+
+The kind of file (submitters, commenters, pr_per_submitter, honored_contributor) and
+its schema version are detected with the header. Columns are matched by name: columns
+missing in the original file are left empty and unknown columns are dropped.
+A file already at the current version but with extra or reordered columns is normalized
+the same way.
+
+By default, the file is converted in place and a backup of the original file is made.
+`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if err := cobra.MinimumNArgs(1)(cmd, args); err != nil {
+			return err
+		}
+		if !fileExist(args[0]) {
+			return fmt.Errorf("ERROR: %s is not an existing file.\n", args[0])
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return performMigrate(args[0], migrateOutput, migrate_requireBackup)
+	},
+}
+
+// Initializes COBRA for this command
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+
+	migrateCmd.Flags().StringVarP(&migrateOutput, "out", "o", "", "Output file name (default: converts the file in place)")
+	migrateCmd.Flags().BoolVarP(&migrate_requireBackup, "backup", "b", true, "Make a backup of the original file (when converting in place)")
+}
+
+// Main function of the MIGRATE command
+func performMigrate(fileName string, suppliedOutputFileName string, isBackup bool) error {
+	table, err := loadCSVtable(fileName)
+	if err != nil {
+		return err
+	}
+
+	targetSchema, migratedRecords := migrateTable(table)
+
+	if isVerbose {
+		fmt.Printf("\"%s\" is a %s file (version %d). Current version is %d.\n",
+			fileName, table.schema.kind, table.schema.version, targetSchema.version)
+	}
+
+	isInPlace := suppliedOutputFileName == "" || suppliedOutputFileName == fileName
+
+	// Nothing to do if the file is already in the current format
+	if isInPlace && table.schema.isCurrent() && validateHeader(trimmedHeader(table.header), targetSchema.columns, false) {
+		fmt.Printf("\"%s\" is already using the current schema (%s v%d)\n", fileName, targetSchema.kind, targetSchema.version)
+		return nil
+	}
+
+	targetFileName := suppliedOutputFileName
+	if isInPlace {
+		targetFileName = fileName
+		if isBackup {
+			backupFileName := compute_backupFileName(fileName, "migrateBackup")
+			if isVerbose {
+				fmt.Printf("Creating backup file: \"%s\" \n", backupFileName)
+			}
+			originalContent, err := os.ReadFile(fileName)
+			if err != nil {
+				return fmt.Errorf("Unable to backup %s: %v\n", fileName, err)
+			}
+			if err := os.WriteFile(backupFileName, originalContent, 0644); err != nil {
+				return fmt.Errorf("Unable to backup %s: %v\n", fileName, err)
+			}
+		}
+	}
+
+	var outputLines []string
+	for _, record := range migratedRecords {
+		outputLines = append(outputLines, formatCSVrecord(record, targetSchema.separator))
+	}
+
+	// Creates or overwrites the output file
+	if err := writeOutputFile(targetFileName, false, targetSchema.headerLine(), outputLines); err != nil {
+		return err
+	}
+
+	if table.schema.isCurrent() {
+		fmt.Printf("Normalized the columns of %d record(s) of \"%s\" (%s v%d, \"%s\")\n",
+			len(migratedRecords), fileName, targetSchema.kind, targetSchema.version, targetFileName)
+	} else {
+		fmt.Printf("Migrated %d record(s) of \"%s\" from %s v%d to v%d (\"%s\")\n",
+			len(migratedRecords), fileName, table.schema.kind, table.schema.version, targetSchema.version, targetFileName)
+	}
+
+	return nil
+}
+
+// Returns the header fields without surrounding white spaces
+func trimmedHeader(header []string) []string {
+	var trimmed []string
+	for _, field := range header {
+		trimmed = append(trimmed, strings.TrimSpace(field))
+	}
+	return trimmed
+}
@@ -0,0 +1,108 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_migrateCommand_integrationTest(t *testing.T) {
+	// Setup environment
+	tempDir := t.TempDir()
+	dataFilename, err := duplicateFile("../test-data/pr_per_submitter-2024-03.csv", tempDir, false)
+	assert.NoError(t, err, "Unexpected data file duplication error")
+
+	actual := new(bytes.Buffer)
+	rootCmd.SetOut(actual)
+	rootCmd.SetErr(actual)
+	rootCmd.SetArgs([]string{"migrate", dataFilename})
+
+	// execute command
+	error := rootCmd.Execute()
+
+	// check results
+	assert.NoError(t, error, "Call should not have failed")
+	content, err := os.ReadFile(dataFilename)
+	assert.NoError(t, err, "Unexpected error reading migrated file")
+	lines := strings.Split(string(content), "\n")
+	assert.Equal(t, "user,PR", lines[0], "Header was not migrated")
+	assert.Equal(t, "\"basil\",69", lines[1], "Data was not migrated")
+
+	backupFiles, _ := filepath.Glob(filepath.Join(tempDir, "migrateBackup_*"))
+	assert.Equal(t, 1, len(backupFiles), "Backup file has not been created")
+}
+
+func Test_performMigrate_alreadyCurrent(t *testing.T) {
+	tempDir := t.TempDir()
+	dataFilename, err := duplicateFile("../test-data/pr_per_submitter-2024-04.csv", tempDir, false)
+	assert.NoError(t, err, "Unexpected data file duplication error")
+
+	err = performMigrate(dataFilename, "", true)
+
+	assert.NoError(t, err, "Call should not have failed")
+	assert.True(t, isFileEquivalent(dataFilename, "../test-data/pr_per_submitter-2024-04.csv"), "Current file should not be modified")
+	backupFiles, _ := filepath.Glob(filepath.Join(tempDir, "migrateBackup_*"))
+	assert.Equal(t, 0, len(backupFiles), "No backup expected when nothing is migrated")
+}
+
+func Test_performMigrate_unknownFile(t *testing.T) {
+	err := performMigrate("../test-data/test-exclusion.txt", "", false)
+	assert.Error(t, err, "Migrating a non data file should fail")
+}
+
+func Test_performMigrate_submissionsToV2(t *testing.T) {
+	outputFileName := filepath.Join(t.TempDir(), "migrated.csv")
+
+	err := performMigrate("../test-data/oneLine-submission-list.csv", outputFileName, false)
+
+	assert.NoError(t, err, "Call should not have failed")
+	table, err := loadCSVtable(outputFileName)
+	assert.NoError(t, err, "Unexpected error reading migrated file")
+	assert.Equal(t, 2, table.schema.version)
+	assert.Equal(t, "", table.get(table.records[0], "first_response_at"), "Lifecycle dates are not available in version 1")
+}
+
+func Test_performMigrate_honorHistoryToCurrent(t *testing.T) {
+	outputFileName := filepath.Join(t.TempDir(), "migrated.csv")
+
+	err := performMigrate("../test-data/monthly/honor_history.csv", outputFileName, false)
+
+	assert.NoError(t, err, "Call should not have failed")
+	table, err := loadCSVtable(outputFileName)
+	assert.NoError(t, err, "Unexpected error reading migrated file")
+	assert.Equal(t, schemaHonor, table.schema.kind)
+	assert.True(t, table.schema.isCurrent(), "The history should have been upgraded from version 2")
+	assert.Equal(t, 3, len(table.records))
+	assert.Equal(t, "42", table.get(table.records[0], "SEED"))
+	assert.Equal(t, "", table.get(table.records[0], "RANK"), "The rank is not available in version 2")
+}
+
+func Test_performMigrate_unwritableOutput(t *testing.T) {
+	err := performMigrate("../test-data/pr_per_submitter-2024-03.csv", filepath.Join(t.TempDir(), "missing_dir", "migrated.csv"), false)
+	assert.ErrorContains(t, err, "Unable to create")
+}
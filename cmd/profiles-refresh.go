@@ -0,0 +1,143 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var profilesIsRefreshAll bool
+var profilesIsAddDataUsers bool
+
+// profilesRefreshCmd represents the "profiles refresh" command
+var profilesRefreshCmd = &cobra.Command{
+	Use:   "refresh",
+	Short: "Retrieves again the expired profiles of the cache",
+	Long: `Retrieves again, from GitHub, the cached profiles that are older than "--profile_ttl"
+days (or all of them with "--all", even if the profiles never expire with a TTL of 0).
+
+With "--data_users", the profiles of all the submitters and commenters found in the data
+directory files are added to the cache (if missing or expired). This allows to generate
+the reports offline afterwards.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !isValidDir(profilesDataDir) {
+			return fmt.Errorf("Supplied DataDir \"%s\" does not exist.", profilesDataDir)
+		}
+		cacheFileName := profilesCacheFileName
+		if cacheFileName == "" {
+			cacheFileName = filepath.Join(profilesDataDir, defaultProfileCacheFileName)
+		}
+		return performProfilesRefresh(cacheFileName, profilesDataDir, profilesIsRefreshAll, profilesIsAddDataUsers, profileTTLdays)
+	},
+}
+
+func init() {
+	profilesCmd.AddCommand(profilesRefreshCmd)
+
+	profilesRefreshCmd.Flags().BoolVarP(&profilesIsRefreshAll, "all", "", false, "Retrieve all the profiles, whatever their age")
+	profilesRefreshCmd.Flags().BoolVarP(&profilesIsAddDataUsers, "data_users", "", false, "Add the submitters and commenters of the data directory to the cache")
+}
+
+// Main function of the "profiles refresh" command
+func performProfilesRefresh(cacheFileName string, dataDir string, isRefreshAll bool, isAddDataUsers bool, ttlDays int) error {
+	cache, err := loadProfileCache(cacheFileName)
+	if err != nil {
+		return err
+	}
+
+	var logins []string
+	for _, profile := range cache {
+		logins = append(logins, profile.login)
+	}
+	if isAddDataUsers {
+		dataUsers, err := listDataDirUsers(dataDir)
+		if err != nil {
+			return err
+		}
+		logins = append(logins, dataUsers...)
+	}
+	sort.Strings(logins)
+
+	// With "--all", the profiles are retrieved whatever their age (and the TTL)
+	isUpdated, resolveErr := resolveProfiles(logins, cache, false, isRefreshAll, ttlDays)
+	// What was retrieved is saved, even if the resolution failed midway
+	if isUpdated {
+		if err := saveProfileCache(cacheFileName, cache); err != nil {
+			return err
+		}
+	}
+	if resolveErr != nil {
+		return resolveErr
+	}
+
+	fmt.Fprintf(os.Stderr, "%d profiles in \"%s\"\n", len(cache), cacheFileName)
+	return nil
+}
+
+// Returns the (distinct) submitters and commenters found in the monthly data files of the data directory
+func listDataDirUsers(dataDir string) ([]string, error) {
+	userSet := make(map[string]bool)
+	var users []string
+	addUser := func(user string) {
+		if user != "" && !userSet[strings.ToLower(user)] {
+			userSet[strings.ToLower(user)] = true
+			users = append(users, user)
+		}
+	}
+
+	submissionFiles, err := listMonthlyDataFiles(dataDir, "submissions", "0000-00", "9999-99")
+	if err != nil {
+		return nil, err
+	}
+	for _, fileName := range submissionFiles {
+		submissions, err := loadSubmissions(fileName)
+		if err != nil {
+			return nil, err
+		}
+		for _, submission := range submissions {
+			addUser(submission.user)
+		}
+	}
+
+	commenterFiles, err := listMonthlyDataFiles(dataDir, "commenters", "0000-00", "9999-99")
+	if err != nil {
+		return nil, err
+	}
+	for _, fileName := range commenterFiles {
+		comments, err := loadComments(fileName)
+		if err != nil {
+			return nil, err
+		}
+		for _, comment := range comments {
+			addUser(comment.commenter)
+		}
+	}
+
+	sort.Strings(users)
+	return users, nil
+}
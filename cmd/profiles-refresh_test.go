@@ -0,0 +1,69 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_listDataDirUsers(t *testing.T) {
+	users, err := listDataDirUsers("../test-data/monthly")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alice", "bob", "carol", "dave", "erin", "frank"}, users)
+}
+
+func Test_performProfilesRefresh_nothingExpired(t *testing.T) {
+	tempDir := t.TempDir()
+	cacheFileName, err := duplicateFile("../test-data/monthly/user_profiles.csv", tempDir, false)
+	assert.NoError(t, err, "Unexpected data file duplication error")
+
+	err = performProfilesRefresh(cacheFileName, "../test-data/monthly", false, false, 0)
+
+	assert.NoError(t, err)
+	assert.True(t, isFileEquivalent(cacheFileName, "../test-data/monthly/user_profiles.csv"), "The cache should not be modified")
+}
+
+func Test_performProfilesRefresh_emptyCache(t *testing.T) {
+	cacheFileName := filepath.Join(t.TempDir(), "profiles.csv")
+
+	err := performProfilesRefresh(cacheFileName, "../test-data/monthly", false, false, defaultProfileTTLdays)
+
+	assert.NoError(t, err)
+	assert.False(t, fileExist(cacheFileName), "No cache should be created when nothing is retrieved")
+}
+
+func Test_performProfilesRefresh_allWithoutTTL(t *testing.T) {
+	// The retrieval fails with an invalid token: this shows that it has been attempted
+	t.Setenv("GITHUB_TOKEN", "invalid-token")
+	tempDir := t.TempDir()
+	cacheFileName, err := duplicateFile("../test-data/monthly/user_profiles.csv", tempDir, false)
+	assert.NoError(t, err, "Unexpected data file duplication error")
+
+	err = performProfilesRefresh(cacheFileName, "../test-data/monthly", true, false, 0)
+
+	assert.Error(t, err, "All the profiles should be retrieved with \"--all\", even with a TTL of 0")
+	assert.True(t, isFileEquivalent(cacheFileName, "../test-data/monthly/user_profiles.csv"), "The cached retrieval dates should not be modified")
+}
@@ -0,0 +1,281 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+	"github.com/spf13/cobra"
+	"golang.org/x/oauth2"
+)
+
+var profilesDataDir string
+var profilesCacheFileName string
+
+// profilesCmd represents the profiles command
+var profilesCmd = &cobra.Command{
+	Use:   "profiles [refresh]",
+	Short: "Manages the cache of GitHub user profiles",
+	Long: `Manages the cache of the GitHub user profiles (name, company, avatar, URL and type of
+account). The cache is shared by the commands needing user information ("honor",
+"report affiliations"). It reduces the GitHub quota usage and allows to generate reports
+offline.
+
+The cache is stored in "user_profiles.csv" in the data directory, unless "--profiles" is
+given. A cached profile is retrieved again when it is older than "--profile_ttl" days.
+`,
+}
+
+// Cobra initialize
+func init() {
+	rootCmd.AddCommand(profilesCmd)
+
+	profilesCmd.PersistentFlags().StringVarP(&profilesDataDir, "data_dir", "", "data", "Directory containing the data files and the profile cache")
+	profilesCmd.PersistentFlags().StringVarP(&profilesCacheFileName, "profiles", "", "", "User profile cache file (default: \""+defaultProfileCacheFileName+"\" in the data directory)")
+	profilesCmd.PersistentFlags().IntVarP(&profileTTLdays, "profile_ttl", "", defaultProfileTTLdays, "Number of days after which a cached profile is retrieved again (0: never)")
+}
+
+// The name of the user profile cache file, when stored in the data directory
+const defaultProfileCacheFileName = "user_profiles.csv"
+
+// The number of days after which a cached profile is retrieved again (see "--profile_ttl")
+const defaultProfileTTLdays = 30
+
+var profileTTLdays int
+
+// The types of GitHub accounts
+const (
+	accountUser         = "User"
+	accountOrganization = "Organization"
+	accountBot          = "Bot"
+	accountUnknown      = "Unknown"
+)
+
+// The GitHub profile information of a user, as stored in the profile cache
+type userProfile struct {
+	login       string
+	name        string
+	company     string
+	avatarURL   string
+	url         string
+	accountType string
+	fetchedAt   string
+}
+
+// Returns true if the profile is older than the TTL (in days) or incomplete (cached with an older version).
+// A TTL of 0 (or less) means that the profiles never expire.
+func (p userProfile) isExpired(ttlDays int, now time.Time) bool {
+	if p.accountType == "" {
+		return true
+	}
+	if ttlDays <= 0 {
+		return false
+	}
+	fetchedAt, err := time.Parse(time.RFC3339, p.fetchedAt)
+	if err != nil {
+		return true
+	}
+	return now.Sub(fetchedAt) > time.Duration(ttlDays)*24*time.Hour
+}
+
+// Returns true if the account is a bot
+func (p userProfile) isBot() bool {
+	return p.accountType == accountBot
+}
+
+// Loads the user profile cache (the key is the lower case login).
+// A missing cache file is not an error: the cache is then empty.
+func loadProfileCache(fileName string) (map[string]userProfile, error) {
+	cache := make(map[string]userProfile)
+	if !fileExist(fileName) {
+		return cache, nil
+	}
+
+	table, err := loadCSVtable(fileName)
+	if err != nil {
+		return nil, err
+	}
+	if table.schema.kind != schemaUserProfiles {
+		return nil, fmt.Errorf("\"%s\" is not a user profile cache (found a \"%s\" file)", fileName, table.schema.kind)
+	}
+
+	for _, record := range table.records {
+		profile := userProfile{
+			login:       table.get(record, "login"),
+			name:        table.get(record, "name"),
+			company:     table.get(record, "company"),
+			avatarURL:   table.get(record, "avatar_url"),
+			url:         table.get(record, "url"),
+			accountType: table.get(record, "type"),
+			fetchedAt:   table.get(record, "fetched_at"),
+		}
+		if profile.login != "" {
+			cache[strings.ToLower(profile.login)] = profile
+		}
+	}
+	return cache, nil
+}
+
+// Writes the user profile cache, sorted by login
+func saveProfileCache(fileName string, cache map[string]userProfile) error {
+	var profiles []userProfile
+	for _, profile := range cache {
+		profiles = append(profiles, profile)
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return strings.ToLower(profiles[i].login) < strings.ToLower(profiles[j].login)
+	})
+
+	var rows [][]string
+	for _, profile := range profiles {
+		rows = append(rows, []string{profile.login, profile.name, profile.company, profile.avatarURL, profile.url, profile.accountType, profile.fetchedAt})
+	}
+	return writeTableOutput(fileName, formatCSV, currentSchema(schemaUserProfiles).columns, nil, rows)
+}
+
+// Makes sure the profile of each user is in the cache and not expired, querying GitHub for the others.
+// In offline mode, GitHub is not queried: missing profiles stay missing and expired ones are kept.
+// When forced, every profile is retrieved again, whatever its age.
+// Returns true if the cache was updated.
+func resolveProfiles(logins []string, cache map[string]userProfile, isOffline bool, isForced bool, ttlDays int) (bool, error) {
+	if isOffline {
+		return false, nil
+	}
+
+	isUpdated := false
+	now := time.Now()
+	retrieved := make(map[string]bool)
+	for _, login := range logins {
+		key := strings.ToLower(login)
+		if retrieved[key] {
+			continue
+		}
+		if profile, exists := cache[key]; exists && !isForced && !profile.isExpired(ttlDays, now) {
+			continue
+		}
+		profile, err := getUserProfileFromGH(login)
+		if err != nil {
+			return isUpdated, err
+		}
+		cache[key] = profile
+		retrieved[key] = true
+		isUpdated = true
+		if isVerbose {
+			fmt.Fprintf(os.Stderr, "Retrieved the profile of %s (%s, company: \"%s\")\n", login, profile.accountType, profile.company)
+		}
+	}
+	return isUpdated, nil
+}
+
+// Gets the profile of a user from the cache file, querying GitHub (and updating the cache)
+// if it is missing or expired
+func lookupUserProfile(login string, cacheFileName string, ttlDays int) (userProfile, error) {
+	cache, err := loadProfileCache(cacheFileName)
+	if err != nil {
+		return userProfile{}, err
+	}
+	isUpdated, err := resolveProfiles([]string{login}, cache, false, false, ttlDays)
+	if err != nil {
+		return userProfile{}, err
+	}
+	if isUpdated {
+		if err := saveProfileCache(cacheFileName, cache); err != nil {
+			return userProfile{}, err
+		}
+	}
+	return cache[strings.ToLower(login)], nil
+}
+
+var botLogin_regexp = regexp.MustCompile(`\[bot\]$`)
+
+// Queries GitHub for the profile of a user
+func getUserProfileFromGH(login string) (userProfile, error) {
+	ghToken := loadGitHubToken(ghTokenVar)
+	src := oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: ghToken},
+	)
+	httpClient := oauth2.NewClient(context.Background(), src)
+	client := githubv4.NewClient(httpClient)
+
+	var userQuery struct {
+		RepositoryOwner struct {
+			Typename  string `graphql:"__typename"`
+			Login     string
+			AvatarUrl string
+			Url       string
+			User      struct {
+				Name    string
+				Company string
+			} `graphql:"... on User"`
+			Organization struct {
+				Name string
+			} `graphql:"... on Organization"`
+		} `graphql:"repositoryOwner(login: $login)"`
+		RateLimit struct {
+			Limit     int
+			Cost      int
+			Remaining int
+			ResetAt   time.Time
+		}
+	}
+	variables := map[string]interface{}{
+		"login": githubv4.String(login),
+	}
+	if err := client.Query(context.Background(), &userQuery, variables); err != nil {
+		return userProfile{}, fmt.Errorf("Error performing user query for %s: %v\n", login, err)
+	}
+
+	checkIfSufficientQuota_2(2,
+		userQuery.RateLimit.Remaining,
+		userQuery.RateLimit.Limit,
+		userQuery.RateLimit.ResetAt)
+
+	profile := userProfile{
+		login:     login,
+		fetchedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	owner := userQuery.RepositoryOwner
+	switch {
+	case owner.Login == "" && botLogin_regexp.MatchString(login):
+		// Bots (applications) are not repository owners
+		profile.accountType = accountBot
+	case owner.Login == "":
+		profile.accountType = accountUnknown
+	case owner.Typename == accountOrganization:
+		profile.accountType = accountOrganization
+		profile.name = owner.Organization.Name
+	default:
+		profile.accountType = accountUser
+		profile.name = owner.User.Name
+		profile.company = strings.TrimSpace(owner.User.Company)
+	}
+	profile.avatarURL = owner.AvatarUrl
+	profile.url = owner.Url
+	return profile, nil
+}
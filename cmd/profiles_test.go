@@ -0,0 +1,128 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_loadProfileCache(t *testing.T) {
+	cache, err := loadProfileCache("../test-data/monthly/user_profiles.csv")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, len(cache))
+	assert.Equal(t, userProfile{login: "bob", name: "Bob Smith", company: "CloudBees, Inc.",
+		avatarURL: "https://avatars.githubusercontent.com/u/1002?v=4", url: "https://github.com/bob",
+		accountType: accountUser, fetchedAt: "2024-04-01T10:00:00Z"}, cache["bob"])
+
+	cache, err = loadProfileCache(filepath.Join(t.TempDir(), "inexistent.csv"))
+	assert.NoError(t, err, "A missing cache is not an error")
+	assert.Empty(t, cache)
+
+	_, err = loadProfileCache("../test-data/monthly/submissions-2024-01.csv")
+	assert.ErrorContains(t, err, "is not a user profile cache")
+}
+
+func Test_saveProfileCache(t *testing.T) {
+	cacheFileName := filepath.Join(t.TempDir(), "profiles.csv")
+	cache := map[string]userProfile{
+		"zoe":   {login: "zoe", name: "Zoe", company: "Acme", url: "https://github.com/zoe", accountType: accountUser, fetchedAt: "2024-04-01T10:00:00Z"},
+		"alice": {login: "Alice", company: "", accountType: accountUser, fetchedAt: "2024-04-02T10:00:00Z"},
+	}
+
+	err := saveProfileCache(cacheFileName, cache)
+
+	assert.NoError(t, err)
+	reloaded, err := loadProfileCache(cacheFileName)
+	assert.NoError(t, err)
+	assert.Equal(t, cache, reloaded)
+}
+
+func Test_resolveProfiles_offline(t *testing.T) {
+	cache := map[string]userProfile{"alice": {login: "alice"}}
+
+	isUpdated, err := resolveProfiles([]string{"Alice", "bob"}, cache, true, false, defaultProfileTTLdays)
+
+	assert.NoError(t, err)
+	assert.False(t, isUpdated, "Nothing should be retrieved in offline mode")
+	assert.Equal(t, 1, len(cache))
+}
+
+func Test_loadProfileCache_version1(t *testing.T) {
+	cacheFileName := filepath.Join(t.TempDir(), "profiles.csv")
+	assert.NoError(t, writeTableOutput(cacheFileName, formatCSV, []string{"login", "company", "fetched_at"}, nil,
+		[][]string{{"alice", "Acme", "2024-04-01T10:00:00Z"}}))
+
+	cache, err := loadProfileCache(cacheFileName)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Acme", cache["alice"].company)
+	assert.True(t, cache["alice"].isExpired(0, time.Now()), "Profiles cached with version 1 are incomplete")
+}
+
+func Test_userProfile_isExpired(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		profile userProfile
+		ttlDays int
+		want    bool
+	}{
+		{"fresh", userProfile{accountType: accountUser, fetchedAt: "2024-04-20T10:00:00Z"}, 30, false},
+		{"old", userProfile{accountType: accountUser, fetchedAt: "2024-03-20T10:00:00Z"}, 30, true},
+		{"never expires", userProfile{accountType: accountUser, fetchedAt: "2020-03-20T10:00:00Z"}, 0, false},
+		{"unknown retrieval date", userProfile{accountType: accountUser, fetchedAt: ""}, 30, true},
+		{"incomplete", userProfile{fetchedAt: "2024-04-20T10:00:00Z"}, 30, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.profile.isExpired(tt.ttlDays, now))
+		})
+	}
+}
+
+func Test_userProfile_isBot(t *testing.T) {
+	assert.True(t, userProfile{accountType: accountBot}.isBot())
+	assert.False(t, userProfile{accountType: accountUser}.isBot())
+}
+
+func Test_resolveProfiles_cached(t *testing.T) {
+	cache, err := loadProfileCache("../test-data/monthly/user_profiles.csv")
+	assert.NoError(t, err)
+
+	// The profiles never expire: nothing has to be retrieved
+	isUpdated, err := resolveProfiles([]string{"ALICE", "bob"}, cache, false, false, 0)
+
+	assert.NoError(t, err)
+	assert.False(t, isUpdated)
+}
+
+func Test_lookupUserProfile_cached(t *testing.T) {
+	profile, err := lookupUserProfile("Dave", "../test-data/monthly/user_profiles.csv", 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Dave Brown", profile.name)
+	assert.Equal(t, "https://github.com/dave", profile.url)
+}
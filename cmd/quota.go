@@ -25,6 +25,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
 	"time"
 
 	"github.com/google/go-github/v55/github"
@@ -152,7 +153,7 @@ func checkIfSufficientQuota(expectedLoad int) {
 		if isRootDebug || isDebugGet {
 			loggers.debug.Printf("Expected load (%d) is higher then limit (%d)\n", expectedLoad, limit)
 		}
-		fmt.Printf("Expected load (%d) is higher then limit (%d)\n Crossing fingers and continuing...\n", expectedLoad, limit)
+		fmt.Fprintf(os.Stderr, "Expected load (%d) is higher then limit (%d)\n Crossing fingers and continuing...\n", expectedLoad, limit)
 		globalIsBigFile = true
 		return
 	}
@@ -188,7 +189,7 @@ func checkIfSufficientQuota_2(expectedLoad int, remaining int, limit int, resetA
 		if isRootDebug || isDebugGet {
 			loggers.debug.Printf("Expected load (%d) is higher then limit (%d)\n", expectedLoad, limit)
 		}
-		fmt.Printf("Expected load (%d) is higher then limit (%d)\n Crossing fingers and continuing...\n", expectedLoad, limit)
+		fmt.Fprintf(os.Stderr, "Expected load (%d) is higher then limit (%d)\n Crossing fingers and continuing...\n", expectedLoad, limit)
 		globalIsBigFile = true
 		return
 	}
@@ -212,6 +213,7 @@ func waitForReset(secondsToReset int) {
 		progressbar.OptionFullWidth(),
 		progressbar.OptionShowCount(),
 		progressbar.OptionClearOnFinish(),
+		progressbar.OptionSetWriter(os.Stderr),
 	)
 
 	for i := 0; i < secondsToReset; i++ {
@@ -0,0 +1,134 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+)
+
+// A Pull Request as stored in a submissions file (generated by "get submitters")
+type submissionRecord struct {
+	org        string
+	repository string
+	number     string
+	url        string
+	state      string
+	createdAt  string
+	mergedAt   string
+	user       string
+	month      string
+	title      string
+	// Available since version 2 of the submissions files (empty otherwise)
+	closedAt        string
+	firstResponseAt string
+	firstApprovalAt string
+}
+
+// Returns the PR specification ("org/project/pr_nbr") of the submission
+func (r submissionRecord) prSpec() string {
+	return fmt.Sprintf("%s/%s/%s", r.org, r.repository, r.number)
+}
+
+// Returns the full name ("org/project") of the submission's repository
+func (r submissionRecord) repositorySpec() string {
+	return r.org + "/" + r.repository
+}
+
+// Returns true if the submission was merged
+func (r submissionRecord) isMerged() bool {
+	return r.mergedAt != ""
+}
+
+// A comment as stored in a commenters file (generated by "get commenters")
+type commentRecord struct {
+	prRef     string
+	commenter string
+	month     string
+}
+
+// Loads a submissions file ("-" for the standard input)
+func loadSubmissions(fileName string) ([]submissionRecord, error) {
+	table, err := loadCSVtable(fileName)
+	if err != nil {
+		return nil, err
+	}
+	if table.schema.kind != schemaSubmitters {
+		return nil, fmt.Errorf("\"%s\" is not a submissions file (found a \"%s\" file)", fileName, table.schema.kind)
+	}
+
+	var submissions []submissionRecord
+	for _, record := range table.records {
+		submissions = append(submissions, submissionRecord{
+			org:        table.get(record, "org"),
+			repository: table.get(record, "repository"),
+			number:     table.get(record, "number"),
+			url:        table.get(record, "url"),
+			state:      table.get(record, "state"),
+			createdAt:  table.get(record, "created_at"),
+			mergedAt:   table.get(record, "merged_at"),
+			user:       userAliases.canonical(table.get(record, "user.login")),
+			month:      table.get(record, "month_year"),
+			title:      table.get(record, "title"),
+
+			closedAt:        table.get(record, "closed_at"),
+			firstResponseAt: table.get(record, "first_response_at"),
+			firstApprovalAt: table.get(record, "first_approval_at"),
+		})
+	}
+	return submissions, nil
+}
+
+// Loads a commenters file ("-" for the standard input)
+func loadComments(fileName string) ([]commentRecord, error) {
+	table, err := loadCSVtable(fileName)
+	if err != nil {
+		return nil, err
+	}
+	if table.schema.kind != schemaCommenters {
+		return nil, fmt.Errorf("\"%s\" is not a commenters file (found a \"%s\" file)", fileName, table.schema.kind)
+	}
+
+	var comments []commentRecord
+	for _, record := range table.records {
+		comments = append(comments, commentRecord{
+			prRef:     table.get(record, "PR_ref"),
+			commenter: userAliases.canonical(table.get(record, "commenter")),
+			month:     table.get(record, "month"),
+		})
+	}
+	return comments, nil
+}
+
+// Builds the PR specification ("org/project/pr_nbr") to author mapping of a set of submissions files
+func loadPRauthors(fileNames []string) (map[string]string, error) {
+	authors := make(map[string]string)
+	for _, fileName := range fileNames {
+		submissions, err := loadSubmissions(fileName)
+		if err != nil {
+			return nil, err
+		}
+		for _, submission := range submissions {
+			authors[submission.prSpec()] = submission.user
+		}
+	}
+	return authors, nil
+}
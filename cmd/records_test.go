@@ -0,0 +1,66 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_loadSubmissions(t *testing.T) {
+	submissions, err := loadSubmissions("../test-data/monthly/submissions-2024-01.csv")
+
+	assert.NoError(t, err, "Unexpected error loading submissions")
+	assert.Equal(t, 5, len(submissions))
+	assert.Equal(t, "jenkinsci/git-plugin/101", submissions[0].prSpec())
+	assert.Equal(t, "jenkinsci/git-plugin", submissions[0].repositorySpec())
+	assert.Equal(t, "alice", submissions[0].user)
+	assert.Equal(t, "2024-01", submissions[0].month)
+	assert.True(t, submissions[0].isMerged())
+	assert.False(t, submissions[1].isMerged())
+}
+
+func Test_loadSubmissions_wrongKind(t *testing.T) {
+	_, err := loadSubmissions("../test-data/pr_per_submitter-2024-04.csv")
+	assert.Error(t, err, "Loading a non submissions file should fail")
+}
+
+func Test_loadComments(t *testing.T) {
+	comments, err := loadComments("../test-data/monthly/commenters-2024-01.csv")
+
+	assert.NoError(t, err, "Unexpected error loading comments")
+	assert.Equal(t, 5, len(comments))
+	assert.Equal(t, commentRecord{"jenkinsci/git-plugin/101", "bob", "2024-01"}, comments[0])
+
+	_, err = loadComments("../test-data/monthly/submissions-2024-01.csv")
+	assert.Error(t, err, "Loading a non commenters file should fail")
+}
+
+func Test_loadPRauthors(t *testing.T) {
+	authors, err := loadPRauthors([]string{"../test-data/monthly/submissions-2024-01.csv", "../test-data/monthly/submissions-2024-02.csv"})
+
+	assert.NoError(t, err, "Unexpected error loading PR authors")
+	assert.Equal(t, 8, len(authors))
+	assert.Equal(t, "carol", authors["jenkins-infra/helpdesk/10"])
+	assert.Equal(t, "dave", authors["jenkinsci/git-plugin/110"])
+}
@@ -23,12 +23,11 @@ package cmd
 
 import (
 	"bufio"
+	"encoding/csv"
 	"fmt"
 	"os"
-	"path/filepath"
 	"regexp"
 	"strings"
-	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -44,6 +43,12 @@ var removeCmd = &cobra.Command{
 A backup of the treated file can be requested (default).
 If the user starts with "list:", the rest of the parameter is interpreted as the path to a 
 list of users to exclude (same format as for the GET command).
+With "--aliases", the data of the other logins of the same persons is removed too.
+
+The type of data file is identified with its header: only the user column ("user.login" 
+for submissions, "commenter" for comments, "user" for the PR counts, etc.) is compared
+with the users to remove. The comparison is exact but not case sensitive: removing "bas"
+will not remove the lines of "basil" or the PRs whose title contains "bas".
 `,
 	Args: func(cmd *cobra.Command, args []string) error {
 		//call requires two parameters (org and month)
@@ -108,6 +113,12 @@ func performRemove(githubUser string, fileToClean_name string, isBackup bool) er
 		return err
 	}
 
+	// Identify the column holding the user in this type of file
+	userColumn, err := findUserColumn(csvToClean_List[0])
+	if err != nil {
+		return fmt.Errorf("ERROR: unable to identify the user column of %s: %v\n", fileToClean_name, err)
+	}
+
 	// Try to clean the file
 	if isVerbose {
 		if len(excludedGithubUsers) == 1 {
@@ -116,7 +127,8 @@ func performRemove(githubUser string, fileToClean_name string, isBackup bool) er
 			fmt.Printf("Removing entries for users %s \n", prettyPrintStringList(excludedGithubUsers))
 		}
 	}
-	cleanedCsv_List := cleanCsvList(csvToClean_List, excludedGithubUsers)
+	// The other logins of the same persons are removed too
+	cleanedCsv_List := cleanCsvList(csvToClean_List, userColumn, userAliases.expandLogins(excludedGithubUsers))
 
 	//Was it useful ?
 	// cleaned file should be shorter than the initial file
@@ -131,10 +143,9 @@ func performRemove(githubUser string, fileToClean_name string, isBackup bool) er
 			}
 
 			//write list with no header and no append
-			out, _ := openOutputCSV(backupFileName, false, true)
-			defer out.Close()
-			writeCSVtoFile(out, false, false, "", csvToClean_List)
-			out.Close()
+			if err := writeOutputFile(backupFileName, true, "", csvToClean_List); err != nil {
+				return err
+			}
 		}
 
 		if isVerbose {
@@ -148,10 +159,9 @@ func performRemove(githubUser string, fileToClean_name string, isBackup bool) er
 		}
 
 		//write list with no header and no append
-		cleanedOut, _ := openOutputCSV(fileToClean_name, false, true)
-		defer cleanedOut.Close()
-		writeCSVtoFile(cleanedOut, false, true, "", cleanedCsv_List)
-		cleanedOut.Close()
+		if err := writeOutputFile(fileToClean_name, true, "", cleanedCsv_List); err != nil {
+			return err
+		}
 	} else {
 		fmt.Printf("Didn't find an entry for user \"%s\" in file \"%s\" \n", githubUser, fileToClean_name)
 	}
@@ -201,12 +211,31 @@ func loadCSVtoClean(fileName string) (error, []string) {
 	return nil, loadedFile
 }
 
-// Removes every list item where the gitHub user is present
-func cleanCsvList(csvToCleanList []string, githubUserList []string) []string {
+// Returns the index of the user column, based on the file's header line
+func findUserColumn(headerLine string) (int, error) {
+	header, err := parseCSVline(headerLine)
+	if err != nil {
+		return -1, err
+	}
+	schema, err := detectSchema(header)
+	if err != nil {
+		return -1, err
+	}
+	userColumn := userColumnOf(schema.kind)
+	for i, column := range header {
+		if strings.TrimSpace(column) == userColumn {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("no user column in %s data", schema.kind)
+}
+
+// Removes every list item (except the header) where the user column holds one of the given users
+func cleanCsvList(csvToCleanList []string, userColumn int, githubUserList []string) []string {
 	var cleanedList []string
 
-	for _, line := range csvToCleanList {
-		if !listItemContainedInLine(line, githubUserList) {
+	for i, line := range csvToCleanList {
+		if i == 0 || !isLineOfListedUser(line, userColumn, githubUserList) {
 			cleanedList = append(cleanedList, line)
 		}
 	}
@@ -214,10 +243,17 @@ func cleanCsvList(csvToCleanList []string, githubUserList []string) []string {
 	return cleanedList
 }
 
-// Returns true if the line contains one of the users in the supplied user list
-func listItemContainedInLine(line string, userList []string) bool {
+// Returns true if the user column of the line is one of the users in the supplied user list (not case sensitive).
+// A line that can't be parsed is never matched.
+func isLineOfListedUser(line string, userColumn int, userList []string) bool {
+	record, err := parseCSVline(line)
+	if err != nil || userColumn < 0 || userColumn >= len(record) {
+		return false
+	}
+
+	lineUser := strings.TrimSpace(record[userColumn])
 	for _, githubUser := range userList {
-		if strings.Contains(line, githubUser) {
+		if strings.EqualFold(lineUser, githubUser) {
 			return true
 		}
 	}
@@ -225,17 +261,16 @@ func listItemContainedInLine(line string, userList []string) bool {
 	return false
 }
 
+// Splits a single CSV line in its fields
+func parseCSVline(line string) ([]string, error) {
+	r := csv.NewReader(strings.NewReader(line))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	r.LazyQuotes = true
+	return r.Read()
+}
+
 // Based on a filename, will return a filename to store the backup
 func compute_removeBackupFileName(fileName string) string {
-	//The validity and existence of the data file are assumed to exist
-	//Compute the current backup timestamp "YYYYMMDD_HHMMSS" (to be prepend to the original file name)
-	dt := time.Now()
-	backupTimeStamp := fmt.Sprint(dt.Format("20060102_150405"))
-
-	// ext := filepath.Ext(fileName)
-	shortFileName := filepath.Base(fileName)
-	path := filepath.Dir(fileName)
-	backup_FileName := fmt.Sprintf("%s/removeBackup_%s__%s", path, backupTimeStamp, shortFileName)
-
-	return (backup_FileName)
+	return compute_backupFileName(fileName, "removeBackup")
 }
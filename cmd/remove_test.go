@@ -194,6 +194,7 @@ func Test_loadCSVtoClean(t *testing.T) {
 func Test_cleanCsvList(t *testing.T) {
 	type args struct {
 		csvToCleanList []string
+		userColumn     int
 		githubUserList []string
 	}
 	tests := []struct {
@@ -205,24 +206,53 @@ func Test_cleanCsvList(t *testing.T) {
 			"happy case",
 			args{
 				csvToCleanList: expectedSubmittersList,
+				userColumn:     7,
 				githubUserList: []string{"olamy"},
 			},
 			cleanedSubmittersList,
 		},
+		{
+			"user case is ignored",
+			args{
+				csvToCleanList: expectedSubmittersList,
+				userColumn:     7,
+				githubUserList: []string{"OLAMY"},
+			},
+			cleanedSubmittersList,
+		},
+		{
+			"user prefix is not removed",
+			args{
+				csvToCleanList: expectedSubmittersList,
+				userColumn:     7,
+				githubUserList: []string{"olam"},
+			},
+			expectedSubmittersList,
+		},
+		{
+			"other columns are ignored",
+			args{
+				csvToCleanList: expectedSubmittersList,
+				userColumn:     7,
+				githubUserList: []string{"jenkinsci", "ldap-plugin", "user.login"},
+			},
+			expectedSubmittersList,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := cleanCsvList(tt.args.csvToCleanList, tt.args.githubUserList); !reflect.DeepEqual(got, tt.want) {
+			if got := cleanCsvList(tt.args.csvToCleanList, tt.args.userColumn, tt.args.githubUserList); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("cleanCsvList() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
-func Test_listItemContainedInLine(t *testing.T) {
+func Test_isLineOfListedUser(t *testing.T) {
 	type args struct {
-		line     string
-		userList []string
+		line       string
+		userColumn int
+		userList   []string
 	}
 	tests := []struct {
 		name string
@@ -232,45 +262,151 @@ func Test_listItemContainedInLine(t *testing.T) {
 		{
 			"detected user from single user list",
 			args{
-				line:     "\"jenkinsci\",\"embeddable-build-status-plugin\",229,\"https://github.com/jenkinsci/embeddable-build-status-plugin/pull/229\",\"closed\",\"2023-08-11T21:18:19Z\",\"2023-08-12T03:55:01Z\",\"MarkEWaite\",\"2023-08\",\"Test with Java 21\"",
-				userList: []string{"MarkEWaite"},
+				line:       "\"jenkinsci\",\"embeddable-build-status-plugin\",229,\"https://github.com/jenkinsci/embeddable-build-status-plugin/pull/229\",\"closed\",\"2023-08-11T21:18:19Z\",\"2023-08-12T03:55:01Z\",\"MarkEWaite\",\"2023-08\",\"Test with Java 21\"",
+				userColumn: 7,
+				userList:   []string{"MarkEWaite"},
 			},
 			true,
 		},
 		{
 			"detected user from multi user list",
 			args{
-				line:     "\"jenkinsci\",\"embeddable-build-status-plugin\",229,\"https://github.com/jenkinsci/embeddable-build-status-plugin/pull/229\",\"closed\",\"2023-08-11T21:18:19Z\",\"2023-08-12T03:55:01Z\",\"MarkEWaite\",\"2023-08\",\"Test with Java 21\"",
-				userList: []string{"user1", "MarkEWaite"},
+				line:       "\"jenkinsci\",\"embeddable-build-status-plugin\",229,\"https://github.com/jenkinsci/embeddable-build-status-plugin/pull/229\",\"closed\",\"2023-08-11T21:18:19Z\",\"2023-08-12T03:55:01Z\",\"MarkEWaite\",\"2023-08\",\"Test with Java 21\"",
+				userColumn: 7,
+				userList:   []string{"user1", "MarkEWaite"},
 			},
 			true,
 		},
 		{
 			"undetected user from multi user list",
 			args{
-				line:     "\"jenkinsci\",\"embeddable-build-status-plugin\",229,\"https://github.com/jenkinsci/embeddable-build-status-plugin/pull/229\",\"closed\",\"2023-08-11T21:18:19Z\",\"2023-08-12T03:55:01Z\",\"MarkEWaite\",\"2023-08\",\"Test with Java 21\"",
-				userList: []string{"user1", "oLamy"},
+				line:       "\"jenkinsci\",\"embeddable-build-status-plugin\",229,\"https://github.com/jenkinsci/embeddable-build-status-plugin/pull/229\",\"closed\",\"2023-08-11T21:18:19Z\",\"2023-08-12T03:55:01Z\",\"MarkEWaite\",\"2023-08\",\"Test with Java 21\"",
+				userColumn: 7,
+				userList:   []string{"user1", "oLamy"},
+			},
+			false,
+		},
+		{
+			"user is not case sensitive",
+			args{
+				line:       "\"jenkinsci\",\"embeddable-build-status-plugin\",229,\"https://github.com/jenkinsci/embeddable-build-status-plugin/pull/229\",\"closed\",\"2023-08-11T21:18:19Z\",\"2023-08-12T03:55:01Z\",\"MarkEWaite\",\"2023-08\",\"Test with Java 21\"",
+				userColumn: 7,
+				userList:   []string{"markewaite"},
+			},
+			true,
+		},
+		{
+			"user prefix is not detected",
+			args{
+				line:       "\"jenkinsci\",\"embeddable-build-status-plugin\",229,\"https://github.com/jenkinsci/embeddable-build-status-plugin/pull/229\",\"closed\",\"2023-08-11T21:18:19Z\",\"2023-08-12T03:55:01Z\",\"MarkEWaite\",\"2023-08\",\"Test with Java 21\"",
+				userColumn: 7,
+				userList:   []string{"Mark"},
+			},
+			false,
+		},
+		{
+			"user in the title is not detected",
+			args{
+				line:       "\"jenkinsci\",\"embeddable-build-status-plugin\",229,\"https://github.com/jenkinsci/embeddable-build-status-plugin/pull/229\",\"closed\",\"2023-08-11T21:18:19Z\",\"2023-08-12T03:55:01Z\",\"MarkEWaite\",\"2023-08\",\"Test with Java 21\"",
+				userColumn: 7,
+				userList:   []string{"Java"},
+			},
+			false,
+		},
+		{
+			"other user column",
+			args{
+				line:       "\"jenkinsci/jenkins/pull/9020\",\"basil\",\"2024-03\"",
+				userColumn: 1,
+				userList:   []string{"bas", "basil"},
+			},
+			true,
+		},
+		{
+			"user column out of range",
+			args{
+				line:       "\"basil\",12",
+				userColumn: 7,
+				userList:   []string{"basil"},
 			},
 			false,
 		},
 		{
 			"empty user list",
 			args{
-				line:     "\"jenkinsci\",\"embeddable-build-status-plugin\",229,\"https://github.com/jenkinsci/embeddable-build-status-plugin/pull/229\",\"closed\",\"2023-08-11T21:18:19Z\",\"2023-08-12T03:55:01Z\",\"MarkEWaite\",\"2023-08\",\"Test with Java 21\"",
-				userList: []string{},
+				line:       "\"jenkinsci\",\"embeddable-build-status-plugin\",229,\"https://github.com/jenkinsci/embeddable-build-status-plugin/pull/229\",\"closed\",\"2023-08-11T21:18:19Z\",\"2023-08-12T03:55:01Z\",\"MarkEWaite\",\"2023-08\",\"Test with Java 21\"",
+				userColumn: 7,
+				userList:   []string{},
 			},
 			false,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := listItemContainedInLine(tt.args.line, tt.args.userList); got != tt.want {
-				t.Errorf("listItemContainedInLine() = %v, want %v", got, tt.want)
+			if got := isLineOfListedUser(tt.args.line, tt.args.userColumn, tt.args.userList); got != tt.want {
+				t.Errorf("isLineOfListedUser() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_findUserColumn(t *testing.T) {
+	tests := []struct {
+		name       string
+		headerLine string
+		want       int
+		wantErr    bool
+	}{
+		{"submissions", "org,repository,number,url,state,created_at,merged_at,user.login,month_year,title", 7, false},
+		{"commenters", "PR_ref,commenter,month", 1, false},
+		{"PR per submitter", "user,PR", 0, false},
+		{"comments count", "commenter,month,comments,PRs", 0, false},
+		{"honor", `"RUN_DATE", "MONTH", "GH_HANDLE", "FULL_NAME", "COMPANY", "GH_HANDLE_URL", "GH_HANDLE_AVATAR", "NBR_PR", "REPOSITORIES"`, 2, false},
+		{"unknown header", "login,PR", -1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findUserColumn(tt.headerLine)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
 			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
 		})
 	}
 }
 
+func Test_performRemove_onlyUserColumn(t *testing.T) {
+	tempDir := t.TempDir()
+	fileName := filepath.Join(tempDir, "commenters-2024-03.csv")
+	content := "PR_ref,commenter,month\n" +
+		"\"jenkinsci/jenkins/pull/9020\",\"basil\",\"2024-03\"\n" +
+		"\"jenkinsci/bas-plugin/pull/12\",\"alice\",\"2024-03\"\n" +
+		"\"jenkinsci/jenkins/pull/9021\",\"Bas\",\"2024-03\"\n"
+	assert.NoError(t, os.WriteFile(fileName, []byte(content), 0644))
+	excludedGithubUsers = nil
+	defer func() { excludedGithubUsers = nil }()
+
+	assert.NoError(t, performRemove("bas", fileName, false))
+
+	cleaned, err := os.ReadFile(fileName)
+	assert.NoError(t, err)
+	assert.Contains(t, string(cleaned), "basil")
+	assert.Contains(t, string(cleaned), "bas-plugin")
+	assert.NotContains(t, string(cleaned), "\"Bas\"")
+}
+
+func Test_performRemove_unknownFile(t *testing.T) {
+	tempDir := t.TempDir()
+	fileName := filepath.Join(tempDir, "unknown.csv")
+	assert.NoError(t, os.WriteFile(fileName, []byte("login,PR\n\"bas\",2\n"), 0644))
+	excludedGithubUsers = nil
+	defer func() { excludedGithubUsers = nil }()
+
+	assert.ErrorContains(t, performRemove("bas", fileName, false), "unable to identify the user column")
+}
+
 func Test_isFileSpec(t *testing.T) {
 	type args struct {
 		input string
@@ -0,0 +1,207 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var affiliationsProfileCacheFileName string
+var affiliationsCompanyMappingFileName string
+var affiliationsIsOffline bool
+
+// reportAffiliationsCmd represents the "report affiliations" command
+var reportAffiliationsCmd = &cobra.Command{
+	Use:   "affiliations",
+	Short: "Breaks down the contributions per affiliation",
+	Long: `Computes, per month and per affiliation, the number of PRs, submitters, comments and
+commenters.
+
+The affiliation of a user is the company of their GitHub profile. The profiles are kept
+in a cache file ("user_profiles.csv" in the data directory, unless "--profiles" is
+given): only the users missing from the cache, or whose profile is older than
+"--profile_ttl" days, are queried on GitHub. With "--offline", GitHub is not queried and
+the users missing from the cache are reported as "(unknown)". Users without company are
+reported as "(none)" and bots as "(bot)".
+
+The company names can be normalized with a mapping file ("--company_mapping"). Each line
+has the form "<company as found on GitHub> = <affiliation>" (case insensitive), for
+example "@cloudbees = CloudBees". Comments start with "#".
+
+If not specified, the output file is "affiliations_<from>_<to>.<format>".`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		excludedUsers, err := validateReportParameters()
+		if err != nil {
+			return err
+		}
+		companyMapping := make(map[string]string)
+		if affiliationsCompanyMappingFileName != "" {
+			companyMapping, err = loadCompanyMapping(affiliationsCompanyMappingFileName)
+			if err != nil {
+				return err
+			}
+		}
+		profileCacheFileName := affiliationsProfileCacheFileName
+		if profileCacheFileName == "" {
+			profileCacheFileName = filepath.Join(reportDataDir, defaultProfileCacheFileName)
+		}
+		return performAffiliationsReport(reportDataDir, reportFromMonth, reportToMonth, reportOutputFileName, reportFormat, excludedUsers,
+			profileCacheFileName, companyMapping, affiliationsIsOffline)
+	},
+}
+
+func init() {
+	reportCmd.AddCommand(reportAffiliationsCmd)
+
+	reportAffiliationsCmd.Flags().StringVarP(&affiliationsProfileCacheFileName, "profiles", "", "", "User profile cache file (default: \""+defaultProfileCacheFileName+"\" in the data directory)")
+	reportAffiliationsCmd.Flags().StringVarP(&affiliationsCompanyMappingFileName, "company_mapping", "m", "", "File normalizing the company names")
+	reportAffiliationsCmd.Flags().BoolVarP(&affiliationsIsOffline, "offline", "", false, "Only use the cached profiles (no GitHub query)")
+	reportAffiliationsCmd.Flags().IntVarP(&profileTTLdays, "profile_ttl", "", defaultProfileTTLdays, "Number of days after which a cached profile is retrieved again (0: never)")
+}
+
+// Main function of the "report affiliations" command
+func performAffiliationsReport(dataDir string, fromMonth string, toMonth string, outputFileName string, format string, excludedUsers []string,
+	profileCacheFileName string, companyMapping map[string]string, isOffline bool) error {
+	fromMonth, toMonth, err := computeReportPeriod(dataDir, fromMonth, toMonth)
+	if err != nil {
+		return err
+	}
+
+	submissions, err := loadSubmissionsForPeriod(dataDir, fromMonth, toMonth, excludedUsers)
+	if err != nil {
+		return err
+	}
+	comments, err := loadCommentsForPeriod(dataDir, fromMonth, toMonth, excludedUsers)
+	if err != nil {
+		return err
+	}
+
+	// Enrich the users with their profile
+	profiles, err := loadProfileCache(profileCacheFileName)
+	if err != nil {
+		return err
+	}
+	var logins []string
+	for _, submission := range submissions {
+		logins = append(logins, submission.user)
+	}
+	for _, comment := range comments {
+		logins = append(logins, comment.commenter)
+	}
+	isUpdated, resolveErr := resolveProfiles(logins, profiles, isOffline, false, profileTTLdays)
+	// What was retrieved is saved, even if the resolution failed midway
+	if isUpdated {
+		if err := saveProfileCache(profileCacheFileName, profiles); err != nil {
+			return err
+		}
+	}
+	if resolveErr != nil {
+		return resolveErr
+	}
+
+	header := []string{"month", "affiliation", "PRs", "submitters", "comments", "commenters"}
+	var rows [][]string
+	for _, activity := range computeAffiliationActivity(submissions, comments, profiles, companyMapping) {
+		rows = append(rows, []string{
+			activity.month,
+			activity.affiliation,
+			strconv.Itoa(activity.prs),
+			strconv.Itoa(len(activity.submitters)),
+			strconv.Itoa(activity.comments),
+			strconv.Itoa(len(activity.commenters)),
+		})
+	}
+
+	outputFileName = computeReportOutputFileName(outputFileName, "affiliations", fromMonth, toMonth, format)
+	if err := writeTableOutput(outputFileName, format, header, []string{"PRs", "submitters", "comments", "commenters"}, rows); err != nil {
+		return err
+	}
+
+	if isVerbose {
+		fmt.Fprintf(os.Stderr, "Affiliations from %s to %s written to \"%s\"\n", fromMonth, toMonth, outputFileName)
+	}
+	return nil
+}
+
+// The activity of the users of an affiliation during a month
+type affiliationActivity struct {
+	month       string
+	affiliation string
+	prs         int
+	submitters  map[string]bool
+	comments    int
+	commenters  map[string]bool
+}
+
+// Computes the activity per month and affiliation.
+// The result is sorted by month, by decreasing number of PRs and comments, and by affiliation.
+func computeAffiliationActivity(submissions []submissionRecord, comments []commentRecord, profiles map[string]userProfile, companyMapping map[string]string) []*affiliationActivity {
+	activities := make(map[string]*affiliationActivity)
+	var result []*affiliationActivity
+	getActivity := func(month string, user string) *affiliationActivity {
+		affiliation := computeAffiliation(user, profiles, companyMapping)
+		key := month + " " + affiliation
+		activity, exists := activities[key]
+		if !exists {
+			activity = &affiliationActivity{
+				month:       month,
+				affiliation: affiliation,
+				submitters:  make(map[string]bool),
+				commenters:  make(map[string]bool),
+			}
+			activities[key] = activity
+			result = append(result, activity)
+		}
+		return activity
+	}
+
+	for _, submission := range submissions {
+		activity := getActivity(submission.month, submission.user)
+		activity.prs++
+		activity.submitters[submission.user] = true
+	}
+	for _, comment := range comments {
+		activity := getActivity(comment.month, comment.commenter)
+		activity.comments++
+		activity.commenters[comment.commenter] = true
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].month != result[j].month {
+			return result[i].month < result[j].month
+		}
+		if result[i].prs != result[j].prs {
+			return result[i].prs > result[j].prs
+		}
+		if result[i].comments != result[j].comments {
+			return result[i].comments > result[j].comments
+		}
+		return result[i].affiliation < result[j].affiliation
+	})
+	return result
+}
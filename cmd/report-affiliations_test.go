@@ -0,0 +1,84 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_computeAffiliationActivity(t *testing.T) {
+	submissions := []submissionRecord{
+		{user: "alice", month: "2024-01"},
+		{user: "alice", month: "2024-01"},
+		{user: "bob", month: "2024-01"},
+		{user: "carol", month: "2024-01"},
+	}
+	comments := []commentRecord{
+		{commenter: "carol", month: "2024-01"},
+		{commenter: "dave", month: "2024-02"},
+	}
+	profiles := map[string]userProfile{
+		"alice": {login: "alice", company: "@cloudbees"},
+		"bob":   {login: "bob", company: "CloudBees"},
+		"carol": {login: "carol", company: "Red Hat"},
+	}
+	mapping := map[string]string{"@cloudbees": "CloudBees"}
+
+	got := computeAffiliationActivity(submissions, comments, profiles, mapping)
+
+	assert.Equal(t, []*affiliationActivity{
+		{month: "2024-01", affiliation: "CloudBees", prs: 3,
+			submitters: map[string]bool{"alice": true, "bob": true}, commenters: map[string]bool{}},
+		{month: "2024-01", affiliation: "Red Hat", prs: 1, comments: 1,
+			submitters: map[string]bool{"carol": true}, commenters: map[string]bool{"carol": true}},
+		{month: "2024-02", affiliation: affiliationUnknown, comments: 1,
+			submitters: map[string]bool{}, commenters: map[string]bool{"dave": true}},
+	}, got)
+}
+
+func Test_reportAffiliationsCommand_integrationTest(t *testing.T) {
+	outputFileName := filepath.Join(t.TempDir(), "affiliations.csv")
+
+	actual := new(bytes.Buffer)
+	rootCmd.SetOut(actual)
+	rootCmd.SetErr(actual)
+	rootCmd.SetArgs([]string{"report", "affiliations", "--data_dir=../test-data/monthly", "--from=2024-03", "--to=2024-03",
+		"--format=csv", "--out=" + outputFileName, "--offline", "--company_mapping=../test-data/company-mapping.txt"})
+
+	error := rootCmd.Execute()
+	// restore the defaults for the other tests
+	affiliationsIsOffline = false
+	affiliationsCompanyMappingFileName = ""
+
+	assert.NoError(t, error, "Call should not have failed")
+	content, err := os.ReadFile(outputFileName)
+	assert.NoError(t, err, "Report should have been generated")
+	assert.Equal(t, "month,affiliation,PRs,submitters,comments,commenters\n"+
+		"\"2024-03\",\"CloudBees\",2,1,4,3\n"+
+		"\"2024-03\",\"(unknown)\",1,1,0,0\n"+
+		"\"2024-03\",\"Red Hat\",1,1,0,0\n", string(content))
+}
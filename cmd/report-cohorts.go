@@ -0,0 +1,213 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var cohortsIsPercent bool
+
+// The number of months after the first contribution at which the retention is measured
+var cohortRetentionOffsets = []int{1, 3, 6, 12}
+
+// reportCohortsCmd represents the "report cohorts" command
+var reportCohortsCmd = &cobra.Command{
+	Use:   "cohorts",
+	Short: "Computes the contributor retention per cohort",
+	Long: `Groups the contributors by the month of their first contribution (their cohort) and
+computes how many of them are still active 1, 3, 6 and 12 months later.
+
+A contributor is active in a month if they submitted or commented a PR during that
+month (based on the submissions and commenters files of the data directory). The whole
+history of the data directory is used, so that the first contribution and the later
+activity are known even if they are outside the reported period.
+
+The reported period selects the cohorts. The retention of a cohort after a number of
+months is left empty when that month is not yet available in the data directory.
+With "--percent", the retention is given as a percentage of the cohort size.
+
+If not specified, the output file is "cohorts_<from>_<to>.<format>".`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		excludedUsers, err := validateReportParameters()
+		if err != nil {
+			return err
+		}
+		return performCohortsReport(reportDataDir, reportFromMonth, reportToMonth, reportOutputFileName, reportFormat, excludedUsers)
+	},
+}
+
+func init() {
+	reportCmd.AddCommand(reportCohortsCmd)
+
+	reportCohortsCmd.Flags().BoolVarP(&cohortsIsPercent, "percent", "", false, "Give the retention as a percentage of the cohort size")
+}
+
+// Main function of the "report cohorts" command
+func performCohortsReport(dataDir string, fromMonth string, toMonth string, outputFileName string, format string, excludedUsers []string) error {
+	fromMonth, toMonth, err := computeReportPeriod(dataDir, fromMonth, toMonth)
+	if err != nil {
+		return err
+	}
+
+	activity, lastMonth, err := loadMonthlyActivity(dataDir, excludedUsers)
+	if err != nil {
+		return err
+	}
+
+	header := []string{"cohort", "contributors"}
+	numericColumns := []string{"contributors"}
+	for _, offset := range cohortRetentionOffsets {
+		header = append(header, retentionColumnName(offset))
+		numericColumns = append(numericColumns, retentionColumnName(offset))
+	}
+
+	var rows [][]string
+	for _, cohort := range computeCohorts(activity, lastMonth, fromMonth, toMonth, cohortRetentionOffsets) {
+		row := []string{cohort.month, strconv.Itoa(cohort.size)}
+		for _, retained := range cohort.retained {
+			row = append(row, formatRetention(retained, cohort.size, cohortsIsPercent))
+		}
+		rows = append(rows, row)
+	}
+
+	outputFileName = computeReportOutputFileName(outputFileName, "cohorts", fromMonth, toMonth, format)
+	if err := writeTableOutput(outputFileName, format, header, numericColumns, rows); err != nil {
+		return err
+	}
+
+	if isVerbose {
+		fmt.Fprintf(os.Stderr, "Cohorts from %s to %s written to \"%s\"\n", fromMonth, toMonth, outputFileName)
+	}
+	return nil
+}
+
+// Loads the whole history of the data directory as the set of active contributors (submitters and commenters) per month.
+// Also returns the last month available.
+func loadMonthlyActivity(dataDir string, excludedUsers []string) (map[string]map[string]bool, string, error) {
+	availableMonths, err := listAvailableMonths(dataDir, "submissions")
+	if err != nil {
+		return nil, "", err
+	}
+	if len(availableMonths) == 0 {
+		return nil, "", fmt.Errorf("No submissions file found in \"%s\"", dataDir)
+	}
+	firstMonth := availableMonths[0]
+	lastMonth := availableMonths[len(availableMonths)-1]
+
+	submissions, err := loadSubmissionsForPeriod(dataDir, firstMonth, lastMonth, excludedUsers)
+	if err != nil {
+		return nil, "", err
+	}
+	comments, err := loadCommentsForPeriod(dataDir, firstMonth, lastMonth, excludedUsers)
+	if err != nil {
+		return nil, "", err
+	}
+
+	activity := make(map[string]map[string]bool)
+	addActivity := func(month string, user string) {
+		if activity[month] == nil {
+			activity[month] = make(map[string]bool)
+		}
+		activity[month][user] = true
+	}
+	for _, submission := range submissions {
+		addActivity(submission.month, submission.user)
+	}
+	for _, comment := range comments {
+		addActivity(comment.month, comment.commenter)
+	}
+	return activity, lastMonth, nil
+}
+
+// The contributors that made their first contribution in a month, and how many of them
+// were active some months later (-1 when the month is not available yet)
+type contributorCohort struct {
+	month    string
+	size     int
+	retained []int
+}
+
+// Computes the cohorts of the period and their retention after each of the offsets (in months).
+// The activity must cover the whole history, up to the last month available.
+func computeCohorts(activity map[string]map[string]bool, lastMonth string, fromMonth string, toMonth string, offsets []int) []contributorCohort {
+	// The first active month of each contributor
+	firstMonths := make(map[string]string)
+	for month, users := range activity {
+		for user := range users {
+			if first, exists := firstMonths[user]; !exists || month < first {
+				firstMonths[user] = month
+			}
+		}
+	}
+
+	var cohorts []contributorCohort
+	for _, month := range monthsOfPeriod(fromMonth, toMonth) {
+		var members []string
+		for user, first := range firstMonths {
+			if first == month {
+				members = append(members, user)
+			}
+		}
+
+		cohort := contributorCohort{month: month, size: len(members)}
+		for _, offset := range offsets {
+			laterMonth := addMonths(month, offset)
+			if laterMonth > lastMonth {
+				cohort.retained = append(cohort.retained, -1)
+				continue
+			}
+			retained := 0
+			for _, user := range members {
+				if activity[laterMonth][user] {
+					retained++
+				}
+			}
+			cohort.retained = append(cohort.retained, retained)
+		}
+		cohorts = append(cohorts, cohort)
+	}
+	return cohorts
+}
+
+// Returns the name of the retention column for the given offset
+func retentionColumnName(offset int) string {
+	if offset == 1 {
+		return "after_1_month"
+	}
+	return fmt.Sprintf("after_%d_months", offset)
+}
+
+// Formats a retention value, as a count or as a percentage of the cohort size (empty if not available)
+func formatRetention(retained int, cohortSize int, isPercent bool) string {
+	if retained < 0 {
+		return ""
+	}
+	if !isPercent {
+		return strconv.Itoa(retained)
+	}
+	return formatPercentage(retained, cohortSize)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_loadMonthlyActivity(t *testing.T) {
+	activity, lastMonth, err := loadMonthlyActivity("../test-data/monthly", []string{"frank"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-03", lastMonth)
+	assert.Equal(t, map[string]bool{"alice": true, "bob": true, "carol": true}, activity["2024-01"])
+	assert.Equal(t, map[string]bool{"alice": true, "bob": true, "dave": true}, activity["2024-02"])
+	assert.Equal(t, map[string]bool{"alice": true, "bob": true, "dave": true, "erin": true}, activity["2024-03"])
+}
+
+func Test_computeCohorts(t *testing.T) {
+	activity, lastMonth, err := loadMonthlyActivity("../test-data/monthly", nil)
+	assert.NoError(t, err)
+
+	got := computeCohorts(activity, lastMonth, "2024-01", "2024-03", []int{1, 2})
+
+	assert.Equal(t, []contributorCohort{
+		{month: "2024-01", size: 3, retained: []int{2, 2}},
+		{month: "2024-02", size: 2, retained: []int{2, -1}},
+		{month: "2024-03", size: 1, retained: []int{-1, -1}},
+	}, got)
+}
+
+func Test_formatRetention(t *testing.T) {
+	tests := []struct {
+		name       string
+		retained   int
+		cohortSize int
+		isPercent  bool
+		want       string
+	}{
+		{"count", 2, 3, false, "2"},
+		{"percentage", 2, 3, true, "66.7"},
+		{"empty cohort", 0, 0, true, "0"},
+		{"not available", -1, 3, false, ""},
+		{"not available percentage", -1, 3, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, formatRetention(tt.retained, tt.cohortSize, tt.isPercent))
+		})
+	}
+}
+
+func Test_retentionColumnName(t *testing.T) {
+	assert.Equal(t, "after_1_month", retentionColumnName(1))
+	assert.Equal(t, "after_12_months", retentionColumnName(12))
+}
+
+func Test_reportCohortsCommand_integrationTest(t *testing.T) {
+	outputFileName := filepath.Join(t.TempDir(), "cohorts.csv")
+
+	actual := new(bytes.Buffer)
+	rootCmd.SetOut(actual)
+	rootCmd.SetErr(actual)
+	rootCmd.SetArgs([]string{"report", "cohorts", "--data_dir=../test-data/monthly", "--from=2024-01", "--to=2024-02",
+		"--format=csv", "--out=" + outputFileName, "--percent"})
+
+	error := rootCmd.Execute()
+	// restore the default for the other tests
+	cohortsIsPercent = false
+
+	assert.NoError(t, error, "Call should not have failed")
+	content, err := os.ReadFile(outputFileName)
+	assert.NoError(t, err, "Report should have been generated")
+	assert.Equal(t, "cohort,contributors,after_1_month,after_3_months,after_6_months,after_12_months\n"+
+		"\"2024-01\",3,\"66.7\",\"\",\"\",\"\"\n"+
+		"\"2024-02\",2,\"100.0\",\"\",\"\",\"\"\n", string(content))
+}
@@ -0,0 +1,225 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	activitySubmissions = "submissions"
+	activityComments    = "comments"
+)
+
+var concentrationGroupBy string
+var concentrationActivity string
+var concentrationTopN int
+
+// reportConcentrationCmd represents the "report concentration" command
+var reportConcentrationCmd = &cobra.Command{
+	Use:   "concentration",
+	Short: "Measures how concentrated the contributions are",
+	Long: `Measures, per organization (or repository) and per month, how much the activity
+depends on a small group of people:
+- contributors: the number of distinct contributors,
+- bus_factor: the minimum number of contributors accounting for half of the activity,
+- gini: the Gini coefficient of the activity per contributor (0 when everybody contributes
+  the same, close to 1 when a single person does everything),
+- top_share: the percentage of the activity done by the "--top" most active contributors.
+
+The activity is either the submitted PRs ("--activity submissions", the default) or the
+comments made on PRs ("--activity comments").
+
+If not specified, the output file is "concentration_<from>_<to>.<format>".`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateGroupBy(concentrationGroupBy); err != nil {
+			return err
+		}
+		if concentrationActivity != activitySubmissions && concentrationActivity != activityComments {
+			return fmt.Errorf("Invalid activity \"%s\" (should be \"%s\" or \"%s\")", concentrationActivity, activitySubmissions, activityComments)
+		}
+		if concentrationTopN < 1 {
+			return fmt.Errorf("The number of top contributors should be at least 1 (found %d)", concentrationTopN)
+		}
+		excludedUsers, err := validateReportParameters()
+		if err != nil {
+			return err
+		}
+		return performConcentrationReport(reportDataDir, reportFromMonth, reportToMonth, reportOutputFileName, reportFormat, excludedUsers,
+			concentrationGroupBy, concentrationActivity, concentrationTopN)
+	},
+}
+
+func init() {
+	reportCmd.AddCommand(reportConcentrationCmd)
+
+	reportConcentrationCmd.Flags().StringVarP(&concentrationGroupBy, "by", "", groupByOrg, "Group the activity by \"org\" or by \"repo\"")
+	reportConcentrationCmd.Flags().StringVarP(&concentrationActivity, "activity", "", activitySubmissions, "Activity to measure (\"submissions\" or \"comments\")")
+	reportConcentrationCmd.Flags().IntVarP(&concentrationTopN, "top", "", 5, "Number of top contributors used to compute the top share")
+}
+
+// Main function of the "report concentration" command
+func performConcentrationReport(dataDir string, fromMonth string, toMonth string, outputFileName string, format string, excludedUsers []string,
+	groupBy string, activity string, topN int) error {
+	fromMonth, toMonth, err := computeReportPeriod(dataDir, fromMonth, toMonth)
+	if err != nil {
+		return err
+	}
+
+	var items []activityItem
+	if activity == activityComments {
+		comments, err := loadCommentsForPeriod(dataDir, fromMonth, toMonth, excludedUsers)
+		if err != nil {
+			return err
+		}
+		for _, comment := range comments {
+			org, project, _, err := validatePRspec(comment.prRef)
+			if err != nil {
+				continue
+			}
+			items = append(items, activityItem{group: computeGroup(org, project, groupBy), month: comment.month, user: comment.commenter})
+		}
+	} else {
+		submissions, err := loadSubmissionsForPeriod(dataDir, fromMonth, toMonth, excludedUsers)
+		if err != nil {
+			return err
+		}
+		for _, submission := range submissions {
+			items = append(items, activityItem{group: computeGroup(submission.org, submission.repository, groupBy), month: submission.month, user: submission.user})
+		}
+	}
+
+	header := []string{groupBy, "month", activity, "contributors", "bus_factor", "gini", "top_share"}
+	var rows [][]string
+	for _, concentration := range computeConcentration(items, topN) {
+		rows = append(rows, []string{
+			concentration.group,
+			concentration.month,
+			strconv.Itoa(concentration.total),
+			strconv.Itoa(concentration.contributors),
+			strconv.Itoa(concentration.busFactor),
+			strconv.FormatFloat(concentration.gini, 'f', 3, 64),
+			formatPercentage(concentration.topTotal, concentration.total),
+		})
+	}
+
+	outputFileName = computeReportOutputFileName(outputFileName, "concentration", fromMonth, toMonth, format)
+	if err := writeTableOutput(outputFileName, format, header, header[2:], rows); err != nil {
+		return err
+	}
+
+	if isVerbose {
+		fmt.Fprintf(os.Stderr, "Concentration from %s to %s written to \"%s\"\n", fromMonth, toMonth, outputFileName)
+	}
+	return nil
+}
+
+// A unit of activity (a PR or a comment) of a user
+type activityItem struct {
+	group string
+	month string
+	user  string
+}
+
+// The concentration indicators of a group for a month
+type contributionConcentration struct {
+	group        string
+	month        string
+	total        int
+	contributors int
+	busFactor    int
+	gini         float64
+	topTotal     int // the activity of the top contributors
+}
+
+// Computes the concentration indicators per group and month. The result is sorted by group and month.
+func computeConcentration(items []activityItem, topN int) []contributionConcentration {
+	type groupMonth struct {
+		group string
+		month string
+	}
+	countsPerGroup := make(map[groupMonth]map[string]int)
+	for _, item := range items {
+		key := groupMonth{item.group, item.month}
+		if countsPerGroup[key] == nil {
+			countsPerGroup[key] = make(map[string]int)
+		}
+		countsPerGroup[key][item.user]++
+	}
+
+	var result []contributionConcentration
+	for key, countsPerUser := range countsPerGroup {
+		var counts []int
+		total := 0
+		for _, count := range countsPerUser {
+			counts = append(counts, count)
+			total += count
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(counts)))
+
+		topTotal := 0
+		for i := 0; i < topN && i < len(counts); i++ {
+			topTotal += counts[i]
+		}
+
+		result = append(result, contributionConcentration{
+			group:        key.group,
+			month:        key.month,
+			total:        total,
+			contributors: len(counts),
+			busFactor:    computeBusFactor(counts),
+			gini:         computeGini(counts),
+			topTotal:     topTotal,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].group != result[j].group {
+			return result[i].group < result[j].group
+		}
+		return result[i].month < result[j].month
+	})
+	return result
+}
+
+// Computes the Gini coefficient of the counts (0 for a perfectly even distribution)
+func computeGini(counts []int) float64 {
+	sorted := append([]int(nil), counts...)
+	sort.Ints(sorted)
+
+	n := len(sorted)
+	total := 0
+	weightedSum := 0
+	for i, count := range sorted {
+		total += count
+		weightedSum += (i + 1) * count
+	}
+	if n == 0 || total == 0 {
+		return 0
+	}
+	return float64(2*weightedSum)/float64(n*total) - float64(n+1)/float64(n)
+}
@@ -0,0 +1,127 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_computeGini(t *testing.T) {
+	tests := []struct {
+		name   string
+		counts []int
+		want   float64
+	}{
+		{"even distribution", []int{2, 2, 2}, 0},
+		{"uneven distribution", []int{3, 1}, 0.25},
+		{"single contributor", []int{7}, 0},
+		{"concentrated", []int{0, 0, 0, 10}, 0.75},
+		{"no contribution", nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, computeGini(tt.counts), 0.0001)
+		})
+	}
+}
+
+func Test_computeConcentration(t *testing.T) {
+	items := []activityItem{
+		{"jenkinsci", "2024-02", "bob"},
+		{"jenkinsci", "2024-01", "alice"},
+		{"jenkinsci", "2024-01", "alice"},
+		{"jenkinsci", "2024-01", "bob"},
+		{"jenkinsci", "2024-01", "alice"},
+		{"jenkins-infra", "2024-01", "carol"},
+	}
+
+	got := computeConcentration(items, 1)
+
+	assert.Equal(t, []contributionConcentration{
+		{group: "jenkins-infra", month: "2024-01", total: 1, contributors: 1, busFactor: 1, gini: 0, topTotal: 1},
+		{group: "jenkinsci", month: "2024-01", total: 4, contributors: 2, busFactor: 1, gini: 0.25, topTotal: 3},
+		{group: "jenkinsci", month: "2024-02", total: 1, contributors: 1, busFactor: 1, gini: 0, topTotal: 1},
+	}, got)
+}
+
+func Test_reportConcentrationCommand_integrationTest(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			"submissions per org",
+			[]string{"--top=1"},
+			"org,month,submissions,contributors,bus_factor,gini,top_share\n" +
+				"\"jenkinsci\",\"2024-02\",3,3,2,\"0.000\",\"33.3\"\n" +
+				"\"jenkinsci\",\"2024-03\",4,3,1,\"0.167\",\"50.0\"\n",
+		},
+		{
+			"comments per repository",
+			[]string{"--activity=comments", "--by=repo"},
+			"repo,month,comments,contributors,bus_factor,gini,top_share\n" +
+				"\"jenkinsci/git-plugin\",\"2024-02\",2,2,1,\"0.000\",\"100.0\"\n" +
+				"\"jenkinsci/jenkins\",\"2024-02\",1,1,1,\"0.000\",\"100.0\"\n" +
+				"\"jenkinsci/jenkins\",\"2024-03\",4,3,1,\"0.167\",\"100.0\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputFileName := filepath.Join(t.TempDir(), "concentration.csv")
+
+			actual := new(bytes.Buffer)
+			rootCmd.SetOut(actual)
+			rootCmd.SetErr(actual)
+			rootCmd.SetArgs(append([]string{"report", "concentration", "--data_dir=../test-data/monthly", "--from=2024-02", "--to=2024-03",
+				"--format=csv", "--out=" + outputFileName}, tt.args...))
+
+			error := rootCmd.Execute()
+			// restore the defaults for the other tests
+			concentrationGroupBy = groupByOrg
+			concentrationActivity = activitySubmissions
+			concentrationTopN = 5
+
+			assert.NoError(t, error, "Call should not have failed")
+			content, err := os.ReadFile(outputFileName)
+			assert.NoError(t, err, "Report should have been generated")
+			assert.Equal(t, tt.want, string(content))
+		})
+	}
+}
+
+func Test_reportConcentrationCommand_invalidActivity(t *testing.T) {
+	actual := new(bytes.Buffer)
+	rootCmd.SetOut(actual)
+	rootCmd.SetErr(actual)
+	rootCmd.SetArgs([]string{"report", "concentration", "--data_dir=../test-data/monthly", "--activity=reviews"})
+
+	error := rootCmd.Execute()
+	concentrationActivity = activitySubmissions
+
+	assert.ErrorContains(t, error, "Invalid activity")
+}
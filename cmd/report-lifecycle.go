@@ -0,0 +1,196 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var lifecycleGroupBy string
+
+// reportLifecycleCmd represents the "report lifecycle" command
+var reportLifecycleCmd = &cobra.Command{
+	Use:   "lifecycle",
+	Short: "Computes the PR lifecycle metrics (time to first response, approval and merge)",
+	Long: `Computes, per organization (or repository) and per month of creation, the median
+and the 90th percentile of:
+- the time to first response (first comment or review of another user than the author),
+- the time to first approval,
+- the time to merge.
+Durations are expressed in hours. PRs that did not (yet) get a response, an approval or
+were not merged are only counted in the number of PRs. As "get submitters" only examines
+the first 20 comments and reviews of each PR, a late first response can be missing.
+
+The lifecycle dates are only available in submissions files generated with version 2 of
+the format (see "get submitters"). Files migrated from version 1 don't contain them.
+
+If not specified, the output file is "lifecycle_<from>_<to>.<format>".`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateGroupBy(lifecycleGroupBy); err != nil {
+			return err
+		}
+		excludedUsers, err := validateReportParameters()
+		if err != nil {
+			return err
+		}
+		return performLifecycleReport(reportDataDir, reportFromMonth, reportToMonth, reportOutputFileName, reportFormat, excludedUsers, lifecycleGroupBy)
+	},
+}
+
+func init() {
+	reportCmd.AddCommand(reportLifecycleCmd)
+
+	reportLifecycleCmd.Flags().StringVarP(&lifecycleGroupBy, "by", "", groupByOrg, "Group the PRs by \"org\" or by \"repo\"")
+}
+
+// Main function of the "report lifecycle" command
+func performLifecycleReport(dataDir string, fromMonth string, toMonth string, outputFileName string, format string, excludedUsers []string, groupBy string) error {
+	fromMonth, toMonth, err := computeReportPeriod(dataDir, fromMonth, toMonth)
+	if err != nil {
+		return err
+	}
+
+	submissions, err := loadSubmissionsForPeriod(dataDir, fromMonth, toMonth, excludedUsers)
+	if err != nil {
+		return err
+	}
+
+	header := []string{groupBy, "month", "PRs",
+		"responded_PRs", "median_first_response_hours", "p90_first_response_hours",
+		"approved_PRs", "median_first_approval_hours", "p90_first_approval_hours",
+		"merged_PRs", "median_merge_hours", "p90_merge_hours"}
+
+	var rows [][]string
+	for _, metrics := range computeLifecycleMetrics(submissions, groupBy) {
+		row := []string{metrics.group, metrics.month, strconv.Itoa(metrics.prs)}
+		for _, durations := range [][]float64{metrics.firstResponseHours, metrics.firstApprovalHours, metrics.mergeHours} {
+			row = append(row,
+				strconv.Itoa(len(durations)),
+				formatHours(percentile(durations, 50)),
+				formatHours(percentile(durations, 90)))
+		}
+		rows = append(rows, row)
+	}
+
+	outputFileName = computeReportOutputFileName(outputFileName, "lifecycle", fromMonth, toMonth, format)
+	if err := writeTableOutput(outputFileName, format, header, header[2:], rows); err != nil {
+		return err
+	}
+
+	if isVerbose {
+		fmt.Fprintf(os.Stderr, "Lifecycle metrics from %s to %s written to \"%s\"\n", fromMonth, toMonth, outputFileName)
+	}
+	return nil
+}
+
+// The lifecycle durations (in hours) of the PRs of a group created in a month
+type lifecycleMetrics struct {
+	group              string
+	month              string
+	prs                int
+	firstResponseHours []float64
+	firstApprovalHours []float64
+	mergeHours         []float64
+}
+
+// Groups the submissions per organization (or repository) and month, and computes their lifecycle durations.
+// The result is sorted by group and month.
+func computeLifecycleMetrics(submissions []submissionRecord, groupBy string) []*lifecycleMetrics {
+	metricsMap := make(map[string]*lifecycleMetrics)
+	var result []*lifecycleMetrics
+	for _, submission := range submissions {
+		group := computeGroup(submission.org, submission.repository, groupBy)
+
+		key := group + " " + submission.month
+		metrics, exists := metricsMap[key]
+		if !exists {
+			metrics = &lifecycleMetrics{group: group, month: submission.month}
+			metricsMap[key] = metrics
+			result = append(result, metrics)
+		}
+
+		metrics.prs++
+		if hours, ok := hoursBetween(submission.createdAt, submission.firstResponseAt); ok {
+			metrics.firstResponseHours = append(metrics.firstResponseHours, hours)
+		}
+		if hours, ok := hoursBetween(submission.createdAt, submission.firstApprovalAt); ok {
+			metrics.firstApprovalHours = append(metrics.firstApprovalHours, hours)
+		}
+		if hours, ok := hoursBetween(submission.createdAt, submission.mergedAt); ok {
+			metrics.mergeHours = append(metrics.mergeHours, hours)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].group != result[j].group {
+			return result[i].group < result[j].group
+		}
+		return result[i].month < result[j].month
+	})
+	return result
+}
+
+// Returns the number of hours between two timestamps (RFC3339). False if one of them is not available or invalid.
+func hoursBetween(start string, end string) (float64, bool) {
+	if start == "" || end == "" {
+		return 0, false
+	}
+	startTime, err := time.Parse(time.RFC3339, start)
+	if err != nil {
+		return 0, false
+	}
+	endTime, err := time.Parse(time.RFC3339, end)
+	if err != nil || endTime.Before(startTime) {
+		return 0, false
+	}
+	return endTime.Sub(startTime).Hours(), true
+}
+
+// Computes a percentile (0-100) of the values, with linear interpolation between the closest ranks.
+// Returns NaN if there is no value.
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	position := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(position-float64(lower))
+}
+
+// Formats a number of hours with one decimal (empty if not available)
+func formatHours(hours float64) string {
+	if math.IsNaN(hours) {
+		return ""
+	}
+	return strconv.FormatFloat(hours, 'f', 1, 64)
+}
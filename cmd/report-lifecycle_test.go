@@ -0,0 +1,140 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_percentile(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		p      float64
+		want   float64
+	}{
+		{"median of odd count", []float64{24, 1, 2}, 50, 2},
+		{"median of even count", []float64{47.5, 200.75}, 50, 124.125},
+		{"p90 interpolated", []float64{1, 2, 24}, 90, 19.6},
+		{"single value", []float64{6}, 90, 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, percentile(tt.values, tt.p), 0.0001)
+		})
+	}
+	assert.True(t, math.IsNaN(percentile(nil, 50)), "No value should give NaN")
+}
+
+func Test_hoursBetween(t *testing.T) {
+	tests := []struct {
+		name      string
+		start     string
+		end       string
+		wantHours float64
+		wantOk    bool
+	}{
+		{"nominal", "2024-01-03T10:00:00Z", "2024-01-05T09:30:00Z", 47.5, true},
+		{"missing end", "2024-01-03T10:00:00Z", "", 0, false},
+		{"invalid start", "yesterday", "2024-01-05T09:30:00Z", 0, false},
+		{"end before start", "2024-01-05T09:30:00Z", "2024-01-03T10:00:00Z", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hours, ok := hoursBetween(tt.start, tt.end)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.wantHours, hours)
+		})
+	}
+}
+
+func Test_computeLifecycleMetrics(t *testing.T) {
+	submissions, err := loadSubmissionsForPeriod("../test-data/monthly", "2024-01", "2024-03", nil)
+	assert.NoError(t, err)
+
+	got := computeLifecycleMetrics(submissions, groupByRepo)
+
+	var groups []string
+	for _, metrics := range got {
+		groups = append(groups, metrics.group+" "+metrics.month)
+	}
+	assert.Equal(t, []string{
+		"jenkins-infra/helpdesk 2024-01",
+		"jenkinsci/git-plugin 2024-01",
+		"jenkinsci/git-plugin 2024-02",
+		"jenkinsci/jenkins 2024-01",
+		"jenkinsci/jenkins 2024-02",
+		"jenkinsci/jenkins 2024-03",
+		"jenkinsci/ldap-plugin 2024-03",
+	}, groups)
+
+	gitPlugin := got[1]
+	assert.Equal(t, 2, gitPlugin.prs)
+	assert.Equal(t, []float64{2, 24}, gitPlugin.firstResponseHours)
+	assert.Equal(t, []float64{24}, gitPlugin.firstApprovalHours)
+	assert.Equal(t, []float64{47.5}, gitPlugin.mergeHours)
+
+	// version 1 file: no response data
+	jenkinsMarch := got[5]
+	assert.Equal(t, 3, jenkinsMarch.prs)
+	assert.Empty(t, jenkinsMarch.firstResponseHours)
+	assert.Equal(t, 3, len(jenkinsMarch.mergeHours))
+}
+
+func Test_reportLifecycleCommand_integrationTest(t *testing.T) {
+	outputFileName := filepath.Join(t.TempDir(), "lifecycle.csv")
+
+	actual := new(bytes.Buffer)
+	rootCmd.SetOut(actual)
+	rootCmd.SetErr(actual)
+	rootCmd.SetArgs([]string{"report", "lifecycle", "--data_dir=../test-data/monthly", "--from=2024-01", "--to=2024-01",
+		"--format=csv", "--out=" + outputFileName})
+
+	error := rootCmd.Execute()
+
+	assert.NoError(t, error, "Call should not have failed")
+	content, err := os.ReadFile(outputFileName)
+	assert.NoError(t, err, "Report should have been generated")
+	assert.Equal(t, "org,month,PRs,responded_PRs,median_first_response_hours,p90_first_response_hours,"+
+		"approved_PRs,median_first_approval_hours,p90_first_approval_hours,merged_PRs,median_merge_hours,p90_merge_hours\n"+
+		"\"jenkins-infra\",\"2024-01\",1,1,\"1.0\",\"1.0\",1,\"2.0\",\"2.0\",1,\"6.8\",\"6.8\"\n"+
+		"\"jenkinsci\",\"2024-01\",4,3,\"2.0\",\"19.6\",2,\"96.0\",\"153.6\",2,\"124.1\",\"185.4\"\n", string(content))
+}
+
+func Test_reportLifecycleCommand_invalidGrouping(t *testing.T) {
+	actual := new(bytes.Buffer)
+	rootCmd.SetOut(actual)
+	rootCmd.SetErr(actual)
+	rootCmd.SetArgs([]string{"report", "lifecycle", "--data_dir=../test-data/monthly", "--by=user"})
+
+	error := rootCmd.Execute()
+	// restore the default for the other tests
+	lifecycleGroupBy = groupByOrg
+
+	assert.ErrorContains(t, error, "Invalid grouping")
+}
@@ -0,0 +1,303 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+	"github.com/spf13/cobra"
+	"golang.org/x/oauth2"
+)
+
+var newContributorsIsDetailed bool
+var newContributorsIsCheckGitHub bool
+
+// reportNewContributorsCmd represents the "report new-contributors" command
+var reportNewContributorsCmd = &cobra.Command{
+	Use:   "new-contributors",
+	Short: "Identifies the first-time contributors",
+	Long: `Identifies, for each month of the reported period, the contributors that submitted
+their first-ever PR.
+
+The contribution history is made of all the submissions files of the data directory up
+to the end of the reported period: the more history is available, the more accurate the
+result. With "--check_github", GitHub is queried for the earliest PR of each new
+contributor in the organizations of the data: contributors with an older PR (not in the
+history) are not counted as new.
+
+The default report gives, per month, the number of new contributors, the number of
+active contributors and the list of new contributors. With "--details", the report
+lists each new contributor with their first PR.
+
+If not specified, the output file is "new-contributors_<from>_<to>.<format>".`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		excludedUsers, err := validateReportParameters()
+		if err != nil {
+			return err
+		}
+		return performNewContributorsReport(reportDataDir, reportFromMonth, reportToMonth, reportOutputFileName, reportFormat, excludedUsers)
+	},
+}
+
+func init() {
+	reportCmd.AddCommand(reportNewContributorsCmd)
+
+	reportNewContributorsCmd.Flags().BoolVarP(&newContributorsIsDetailed, "details", "", false, "List each new contributor with their first PR")
+	reportNewContributorsCmd.Flags().BoolVarP(&newContributorsIsCheckGitHub, "check_github", "", false, "Check on GitHub that new contributors have no older PR (requires a GitHub token)")
+}
+
+// Main function of the "report new-contributors" command
+func performNewContributorsReport(dataDir string, fromMonth string, toMonth string, outputFileName string, format string, excludedUsers []string) error {
+	fromMonth, toMonth, err := computeReportPeriod(dataDir, fromMonth, toMonth)
+	if err != nil {
+		return err
+	}
+
+	history, err := loadSubmissionsHistory(dataDir, toMonth, excludedUsers)
+	if err != nil {
+		return err
+	}
+
+	firstContributions := computeFirstContributions(history)
+
+	if newContributorsIsCheckGitHub {
+		if err := checkFirstContributionsOnGitHub(firstContributions, fromMonth, toMonth); err != nil {
+			return err
+		}
+	}
+
+	var header []string
+	var numericColumns []string
+	var rows [][]string
+	if newContributorsIsDetailed {
+		header = []string{"user", "first_month", "first_PR", "first_PR_url"}
+		for _, first := range sortedFirstContributions(firstContributions, fromMonth, toMonth) {
+			rows = append(rows, []string{first.user, first.month, first.prSpec(), first.url})
+		}
+	} else {
+		header = []string{"month", "new_contributors", "active_contributors", "new_contributors_list"}
+		numericColumns = []string{"new_contributors", "active_contributors"}
+		for _, monthData := range countNewContributorsPerMonth(history, firstContributions, fromMonth, toMonth) {
+			rows = append(rows, []string{
+				monthData.month,
+				strconv.Itoa(len(monthData.newContributors)),
+				strconv.Itoa(monthData.activeContributors),
+				stringifySlice(monthData.newContributors),
+			})
+		}
+	}
+
+	outputFileName = computeReportOutputFileName(outputFileName, "new-contributors", fromMonth, toMonth, format)
+	if err := writeTableOutput(outputFileName, format, header, numericColumns, rows); err != nil {
+		return err
+	}
+
+	if isVerbose {
+		fmt.Fprintf(os.Stderr, "New contributors from %s to %s written to \"%s\"\n", fromMonth, toMonth, outputFileName)
+	}
+	return nil
+}
+
+// Returns, for each submitter, their first PR in the submissions (the earliest created)
+func computeFirstContributions(submissions []submissionRecord) map[string]submissionRecord {
+	firstContributions := make(map[string]submissionRecord)
+	for _, submission := range submissions {
+		first, exists := firstContributions[submission.user]
+		if !exists || isCreatedBefore(submission, first) {
+			firstContributions[submission.user] = submission
+		}
+	}
+	return firstContributions
+}
+
+// Returns true if the first submission was created before the second one.
+// Submissions created at the same time are ordered by PR specification (to be deterministic).
+func isCreatedBefore(first submissionRecord, second submissionRecord) bool {
+	if first.month != second.month {
+		return first.month < second.month
+	}
+	if first.createdAt != second.createdAt {
+		return first.createdAt < second.createdAt
+	}
+	return first.prSpec() < second.prSpec()
+}
+
+// Returns the first contributions made within the period, sorted by date
+func sortedFirstContributions(firstContributions map[string]submissionRecord, fromMonth string, toMonth string) []submissionRecord {
+	var sorted []submissionRecord
+	for _, first := range firstContributions {
+		if first.month >= fromMonth && first.month <= toMonth {
+			sorted = append(sorted, first)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return isCreatedBefore(sorted[i], sorted[j])
+	})
+	return sorted
+}
+
+// New and active contributors of a month
+type monthlyNewContributors struct {
+	month              string
+	newContributors    []string
+	activeContributors int
+}
+
+// Computes, for each month of the period, the new contributors (sorted by handle) and the number of active contributors
+func countNewContributorsPerMonth(submissions []submissionRecord, firstContributions map[string]submissionRecord, fromMonth string, toMonth string) []monthlyNewContributors {
+	activeContributors := make(map[string]map[string]bool)
+	for _, submission := range submissions {
+		if activeContributors[submission.month] == nil {
+			activeContributors[submission.month] = make(map[string]bool)
+		}
+		activeContributors[submission.month][submission.user] = true
+	}
+
+	var result []monthlyNewContributors
+	for _, month := range monthsOfPeriod(fromMonth, toMonth) {
+		monthData := monthlyNewContributors{month: month, activeContributors: len(activeContributors[month])}
+		for user := range activeContributors[month] {
+			if first, exists := firstContributions[user]; exists && first.month == month {
+				monthData.newContributors = append(monthData.newContributors, user)
+			}
+		}
+		sort.Slice(monthData.newContributors, func(i, j int) bool {
+			return strings.ToLower(monthData.newContributors[i]) < strings.ToLower(monthData.newContributors[j])
+		})
+		result = append(result, monthData)
+	}
+	return result
+}
+
+// Queries GitHub for the earliest PR of the contributors that are new in the period.
+// If an older PR is found, it replaces the first contribution (so that the contributor is not new anymore).
+func checkFirstContributionsOnGitHub(firstContributions map[string]submissionRecord, fromMonth string, toMonth string) error {
+	// The organizations of the contributions
+	orgSet := make(map[string]bool)
+	var orgs []string
+	for _, first := range firstContributions {
+		if !orgSet[first.org] {
+			orgSet[first.org] = true
+			orgs = append(orgs, first.org)
+		}
+	}
+	sort.Strings(orgs)
+
+	for _, first := range sortedFirstContributions(firstContributions, fromMonth, toMonth) {
+		earliest, err := getEarliestPRfromGH(first.user, orgs)
+		if err != nil {
+			return err
+		}
+		if earliest.createdAt != "" && isCreatedBefore(earliest, first) {
+			if isVerbose {
+				fmt.Fprintf(os.Stderr, "%s is not a new contributor (first PR: %s)\n", first.user, earliest.url)
+			}
+			firstContributions[first.user] = earliest
+		}
+	}
+	return nil
+}
+
+// Builds the GitHub search query of the PRs of a user (with any of their logins, see "--aliases")
+// in the given organizations, the oldest first
+func buildEarliestPRsearchQuery(user string, orgs []string) string {
+	var terms []string
+	for _, org := range orgs {
+		terms = append(terms, "org:"+org)
+	}
+	terms = append(terms, "is:pr")
+	for _, login := range userAliases.expandLogins([]string{user}) {
+		terms = append(terms, "author:"+login)
+	}
+	terms = append(terms, "sort:created-asc")
+	return strings.Join(terms, " ")
+}
+
+// Gets the earliest PR of a user in the given organizations
+func getEarliestPRfromGH(user string, orgs []string) (submissionRecord, error) {
+	ghToken := loadGitHubToken(ghTokenVar)
+	src := oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: ghToken},
+	)
+	httpClient := oauth2.NewClient(context.Background(), src)
+	client := githubv4.NewClient(httpClient)
+
+	var earliestPRquery struct {
+		Search struct {
+			Edges []struct {
+				Node struct {
+					PullRequest struct {
+						Url        string
+						Number     int
+						CreatedAt  time.Time
+						Repository struct {
+							Name  string
+							Owner struct {
+								Login string
+							}
+						}
+					} `graphql:"... on PullRequest"`
+				}
+			}
+		} `graphql:"search(first: 1, query: $searchQuery, type: ISSUE)"`
+		RateLimit struct {
+			Limit     int
+			Cost      int
+			Remaining int
+			ResetAt   time.Time
+		}
+	}
+
+	variables := map[string]interface{}{
+		"searchQuery": githubv4.String(buildEarliestPRsearchQuery(user, orgs)),
+	}
+
+	if err := client.Query(context.Background(), &earliestPRquery, variables); err != nil {
+		return submissionRecord{}, fmt.Errorf("Error performing earliest PR query for %s: %v\n", user, err)
+	}
+
+	checkIfSufficientQuota_2(2,
+		earliestPRquery.RateLimit.Remaining,
+		earliestPRquery.RateLimit.Limit,
+		earliestPRquery.RateLimit.ResetAt)
+
+	if len(earliestPRquery.Search.Edges) == 0 {
+		return submissionRecord{}, nil
+	}
+	pr := earliestPRquery.Search.Edges[0].Node.PullRequest
+	return submissionRecord{
+		org:        pr.Repository.Owner.Login,
+		repository: pr.Repository.Name,
+		number:     strconv.Itoa(pr.Number),
+		url:        pr.Url,
+		createdAt:  pr.CreatedAt.UTC().Format(time.RFC3339),
+		user:       user,
+		month:      pr.CreatedAt.UTC().Format("2006-01"),
+	}, nil
+}
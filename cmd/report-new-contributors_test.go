@@ -0,0 +1,129 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_computeFirstContributions(t *testing.T) {
+	submissions := []submissionRecord{
+		{org: "jenkinsci", repository: "jenkins", number: "20", createdAt: "2024-02-10T10:00:00Z", user: "alice", month: "2024-02"},
+		{org: "jenkinsci", repository: "jenkins", number: "10", createdAt: "2024-01-20T10:00:00Z", user: "alice", month: "2024-01"},
+		{org: "jenkinsci", repository: "jenkins", number: "12", createdAt: "2024-01-05T10:00:00Z", user: "bob", month: "2024-01"},
+		{org: "jenkinsci", repository: "git-plugin", number: "3", createdAt: "2024-01-05T10:00:00Z", user: "bob", month: "2024-01"},
+	}
+
+	got := computeFirstContributions(submissions)
+
+	assert.Len(t, got, 2)
+	assert.Equal(t, "jenkinsci/jenkins/10", got["alice"].prSpec())
+	// same creation time: the PR specification decides
+	assert.Equal(t, "jenkinsci/git-plugin/3", got["bob"].prSpec())
+}
+
+func Test_countNewContributorsPerMonth(t *testing.T) {
+	history, err := loadSubmissionsHistory("../test-data/monthly", "2024-03", nil)
+	assert.NoError(t, err)
+	firstContributions := computeFirstContributions(history)
+
+	got := countNewContributorsPerMonth(history, firstContributions, "2024-02", "2024-04")
+
+	assert.Equal(t, []monthlyNewContributors{
+		{month: "2024-02", newContributors: []string{"dave"}, activeContributors: 3},
+		{month: "2024-03", newContributors: []string{"erin"}, activeContributors: 3},
+		{month: "2024-04", newContributors: nil, activeContributors: 0},
+	}, got)
+}
+
+func Test_sortedFirstContributions(t *testing.T) {
+	history, err := loadSubmissionsHistory("../test-data/monthly", "2024-03", nil)
+	assert.NoError(t, err)
+	firstContributions := computeFirstContributions(history)
+
+	var got []string
+	for _, first := range sortedFirstContributions(firstContributions, "2024-01", "2024-02") {
+		got = append(got, first.user)
+	}
+	assert.Equal(t, []string{"alice", "bob", "carol", "dave"}, got)
+}
+
+func Test_reportNewContributorsCommand_integrationTest(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			"monthly counts",
+			[]string{},
+			"month,new_contributors,active_contributors,new_contributors_list\n" +
+				"\"2024-02\",1,3,\"dave\"\n" +
+				"\"2024-03\",1,3,\"erin\"\n",
+		},
+		{
+			"details",
+			[]string{"--details"},
+			"user,first_month,first_PR,first_PR_url\n" +
+				"\"dave\",\"2024-02\",\"jenkinsci/git-plugin/110\",\"https://github.com/jenkinsci/git-plugin/pull/110\"\n" +
+				"\"erin\",\"2024-03\",\"jenkinsci/ldap-plugin/5\",\"https://github.com/jenkinsci/ldap-plugin/pull/5\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputFileName := filepath.Join(t.TempDir(), "new.csv")
+
+			actual := new(bytes.Buffer)
+			rootCmd.SetOut(actual)
+			rootCmd.SetErr(actual)
+			rootCmd.SetArgs(append([]string{"report", "new-contributors", "--data_dir=../test-data/monthly", "--from=2024-02", "--to=2024-03",
+				"--format=csv", "--out=" + outputFileName}, tt.args...))
+
+			error := rootCmd.Execute()
+			// restore the default for the other tests
+			newContributorsIsDetailed = false
+
+			assert.NoError(t, error, "Call should not have failed")
+			content, err := os.ReadFile(outputFileName)
+			assert.NoError(t, err, "Report should have been generated")
+			assert.Equal(t, tt.want, string(content))
+		})
+	}
+}
+
+func Test_buildEarliestPRsearchQuery(t *testing.T) {
+	assert.Equal(t, "org:jenkinsci org:jenkins-infra is:pr author:bob sort:created-asc",
+		buildEarliestPRsearchQuery("bob", []string{"jenkinsci", "jenkins-infra"}))
+
+	var err error
+	userAliases, err = loadIdentityAliases("../test-data/aliases.txt")
+	assert.NoError(t, err)
+	defer func() { userAliases = identityAliases{} }()
+
+	assert.Equal(t, "org:jenkinsci is:pr author:dave author:erin sort:created-asc",
+		buildEarliestPRsearchQuery("dave", []string{"jenkinsci"}), "The earlier PRs of the other logins should be found too")
+}
@@ -0,0 +1,210 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// reportReposCmd represents the "report repos" command
+var reportReposCmd = &cobra.Command{
+	Use:   "repos",
+	Short: "Summarizes the health of each repository",
+	Long: `Aggregates the submissions and commenters of the reported period per repository:
+- PRs: the number of PRs created in the period,
+- merged_PRs and merge_rate: the number and the percentage of merged PRs,
+- contributors: the number of distinct PR authors,
+- reviewers: the number of distinct users that commented or reviewed the PRs (authors
+  commenting their own PRs are not counted),
+- unreviewed_share: the percentage of PRs without any comment or review of another user,
+- bus_factor: the minimum number of contributors that authored half of the PRs.
+
+A PR is considered as reviewed if the commenters file lists another user than its
+author, or if the submissions file records a first response (version 2 of the format).
+
+The repositories are sorted by decreasing number of PRs.
+If not specified, the output file is "repos_<from>_<to>.<format>".`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		excludedUsers, err := validateReportParameters()
+		if err != nil {
+			return err
+		}
+		return performReposReport(reportDataDir, reportFromMonth, reportToMonth, reportOutputFileName, reportFormat, excludedUsers)
+	},
+}
+
+func init() {
+	reportCmd.AddCommand(reportReposCmd)
+}
+
+// Main function of the "report repos" command
+func performReposReport(dataDir string, fromMonth string, toMonth string, outputFileName string, format string, excludedUsers []string) error {
+	fromMonth, toMonth, err := computeReportPeriod(dataDir, fromMonth, toMonth)
+	if err != nil {
+		return err
+	}
+
+	submissions, err := loadSubmissionsForPeriod(dataDir, fromMonth, toMonth, excludedUsers)
+	if err != nil {
+		return err
+	}
+	comments, err := loadCommentsForPeriod(dataDir, fromMonth, toMonth, excludedUsers)
+	if err != nil {
+		return err
+	}
+
+	header := []string{"repository", "PRs", "merged_PRs", "merge_rate", "contributors", "reviewers", "unreviewed_share", "bus_factor"}
+	var rows [][]string
+	for _, health := range computeRepositoryHealth(submissions, comments) {
+		rows = append(rows, []string{
+			health.repository,
+			strconv.Itoa(health.prs),
+			strconv.Itoa(health.mergedPRs),
+			formatPercentage(health.mergedPRs, health.prs),
+			strconv.Itoa(health.contributors),
+			strconv.Itoa(health.reviewers),
+			formatPercentage(health.unreviewedPRs, health.prs),
+			strconv.Itoa(health.busFactor),
+		})
+	}
+
+	outputFileName = computeReportOutputFileName(outputFileName, "repos", fromMonth, toMonth, format)
+	if err := writeTableOutput(outputFileName, format, header, header[1:], rows); err != nil {
+		return err
+	}
+
+	if isVerbose {
+		fmt.Fprintf(os.Stderr, "Repository health from %s to %s written to \"%s\"\n", fromMonth, toMonth, outputFileName)
+	}
+	return nil
+}
+
+// The health indicators of a repository
+type repositoryHealth struct {
+	repository    string
+	prs           int
+	mergedPRs     int
+	contributors  int
+	reviewers     int
+	unreviewedPRs int
+	busFactor     int
+}
+
+// Computes the health indicators of each repository of the submissions.
+// Comments on PRs that are not part of the submissions are ignored.
+// The result is sorted by decreasing number of PRs (and by repository name).
+func computeRepositoryHealth(submissions []submissionRecord, comments []commentRecord) []repositoryHealth {
+	// The users, other than the author, that reacted on each PR
+	authors := make(map[string]string)
+	for _, submission := range submissions {
+		authors[submission.prSpec()] = submission.user
+	}
+	reviewersPerPR := make(map[string]map[string]bool)
+	for _, comment := range comments {
+		author, exists := authors[comment.prRef]
+		if !exists || strings.EqualFold(comment.commenter, author) {
+			continue
+		}
+		if reviewersPerPR[comment.prRef] == nil {
+			reviewersPerPR[comment.prRef] = make(map[string]bool)
+		}
+		reviewersPerPR[comment.prRef][comment.commenter] = true
+	}
+
+	type repositoryData struct {
+		health          repositoryHealth
+		prsPerSubmitter map[string]int
+		reviewers       map[string]bool
+	}
+	repositories := make(map[string]*repositoryData)
+	for _, submission := range submissions {
+		repository := submission.repositorySpec()
+		data, exists := repositories[repository]
+		if !exists {
+			data = &repositoryData{
+				health:          repositoryHealth{repository: repository},
+				prsPerSubmitter: make(map[string]int),
+				reviewers:       make(map[string]bool),
+			}
+			repositories[repository] = data
+		}
+
+		data.health.prs++
+		if submission.isMerged() {
+			data.health.mergedPRs++
+		}
+		data.prsPerSubmitter[submission.user]++
+		for reviewer := range reviewersPerPR[submission.prSpec()] {
+			data.reviewers[reviewer] = true
+		}
+		if len(reviewersPerPR[submission.prSpec()]) == 0 && submission.firstResponseAt == "" {
+			data.health.unreviewedPRs++
+		}
+	}
+
+	var result []repositoryHealth
+	for _, data := range repositories {
+		var counts []int
+		for _, count := range data.prsPerSubmitter {
+			counts = append(counts, count)
+		}
+		data.health.contributors = len(data.prsPerSubmitter)
+		data.health.reviewers = len(data.reviewers)
+		data.health.busFactor = computeBusFactor(counts)
+		result = append(result, data.health)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].prs != result[j].prs {
+			return result[i].prs > result[j].prs
+		}
+		return result[i].repository < result[j].repository
+	})
+	return result
+}
+
+// Computes the bus factor: the minimum number of contributors that together account for
+// at least half of the total (the counts are the number of PRs of each contributor)
+func computeBusFactor(counts []int) int {
+	sorted := append([]int(nil), counts...)
+	sort.Sort(sort.Reverse(sort.IntSlice(sorted)))
+
+	total := 0
+	for _, count := range sorted {
+		total += count
+	}
+
+	covered := 0
+	for i, count := range sorted {
+		covered += count
+		if covered*2 >= total {
+			return i + 1
+		}
+	}
+	return 0
+}
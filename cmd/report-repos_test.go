@@ -0,0 +1,85 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_computeBusFactor(t *testing.T) {
+	tests := []struct {
+		name   string
+		counts []int
+		want   int
+	}{
+		{"single contributor", []int{5}, 1},
+		{"dominant contributor", []int{1, 4, 2}, 1},
+		{"even distribution", []int{1, 1, 1, 1}, 2},
+		{"odd total", []int{2, 2, 1}, 2},
+		{"no contribution", nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, computeBusFactor(tt.counts))
+		})
+	}
+}
+
+func Test_computeRepositoryHealth(t *testing.T) {
+	submissions, err := loadSubmissionsForPeriod("../test-data/monthly", "2024-01", "2024-03", nil)
+	assert.NoError(t, err)
+	comments, err := loadCommentsForPeriod("../test-data/monthly", "2024-01", "2024-03", nil)
+	assert.NoError(t, err)
+
+	got := computeRepositoryHealth(submissions, comments)
+
+	assert.Equal(t, []repositoryHealth{
+		{repository: "jenkinsci/jenkins", prs: 7, mergedPRs: 6, contributors: 3, reviewers: 4, unreviewedPRs: 2, busFactor: 1},
+		{repository: "jenkinsci/git-plugin", prs: 3, mergedPRs: 2, contributors: 2, reviewers: 3, unreviewedPRs: 0, busFactor: 1},
+		{repository: "jenkins-infra/helpdesk", prs: 1, mergedPRs: 1, contributors: 1, reviewers: 1, unreviewedPRs: 0, busFactor: 1},
+		{repository: "jenkinsci/ldap-plugin", prs: 1, mergedPRs: 0, contributors: 1, reviewers: 0, unreviewedPRs: 1, busFactor: 1},
+	}, got)
+}
+
+func Test_reportReposCommand_integrationTest(t *testing.T) {
+	outputFileName := filepath.Join(t.TempDir(), "repos.csv")
+
+	actual := new(bytes.Buffer)
+	rootCmd.SetOut(actual)
+	rootCmd.SetErr(actual)
+	rootCmd.SetArgs([]string{"report", "repos", "--data_dir=../test-data/monthly", "--from=2024-03", "--to=2024-03",
+		"--format=csv", "--out=" + outputFileName})
+
+	error := rootCmd.Execute()
+
+	assert.NoError(t, error, "Call should not have failed")
+	content, err := os.ReadFile(outputFileName)
+	assert.NoError(t, err, "Report should have been generated")
+	assert.Equal(t, "repository,PRs,merged_PRs,merge_rate,contributors,reviewers,unreviewed_share,bus_factor\n"+
+		"\"jenkinsci/jenkins\",3,3,\"100.0\",2,3,\"33.3\",1\n"+
+		"\"jenkinsci/ldap-plugin\",1,0,\"0.0\",1,0,\"100.0\",1\n", string(content))
+}
@@ -0,0 +1,185 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var topSubmittersMinPRs int
+var topSubmittersLimit int
+var topSubmittersIsMergedOnly bool
+
+// reportTopSubmittersCmd represents the "report top-submitters" command
+var reportTopSubmittersCmd = &cobra.Command{
+	Use:   "top-submitters",
+	Short: "Ranks the PR submitters over a period",
+	Long: `Ranks the contributors by number of PRs submitted over the reported period.
+
+For each submitter, the report gives the rank, the number of PRs, the number of merged
+PRs, the number of distinct repositories and the first and last month of activity in the period.
+
+Submitters with the same number of PRs share the same rank ("1, 2, 2, 4" ranking) and are
+listed by alphabetical order of their GitHub handle, so that the report is reproducible.
+With "--top", the list is limited to the given number of submitters, but the submitters
+sharing the rank of the last one are kept.
+
+If not specified, the output file is "top-submitters_<from>_<to>.<format>".`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		excludedUsers, err := validateReportParameters()
+		if err != nil {
+			return err
+		}
+		return performTopSubmittersReport(reportDataDir, reportFromMonth, reportToMonth, reportOutputFileName, reportFormat, excludedUsers)
+	},
+}
+
+func init() {
+	reportCmd.AddCommand(reportTopSubmittersCmd)
+
+	reportTopSubmittersCmd.Flags().IntVarP(&topSubmittersMinPRs, "min_prs", "", 1, "Minimum number of PRs to be listed")
+	reportTopSubmittersCmd.Flags().IntVarP(&topSubmittersLimit, "top", "", 0, "Maximum number of submitters to list (0: no limit)")
+	reportTopSubmittersCmd.Flags().BoolVarP(&topSubmittersIsMergedOnly, "merged_only", "", false, "Rank on the merged PRs only")
+}
+
+// A ranked submitter
+type rankedSubmitter struct {
+	rank         int
+	user         string
+	prs          int
+	mergedPRs    int
+	repositories int
+	firstMonth   string
+	lastMonth    string
+}
+
+// Main function of the "report top-submitters" command
+func performTopSubmittersReport(dataDir string, fromMonth string, toMonth string, outputFileName string, format string, excludedUsers []string) error {
+	fromMonth, toMonth, err := computeReportPeriod(dataDir, fromMonth, toMonth)
+	if err != nil {
+		return err
+	}
+
+	submissions, err := loadSubmissionsForPeriod(dataDir, fromMonth, toMonth, excludedUsers)
+	if err != nil {
+		return err
+	}
+
+	ranking := rankSubmitters(submissions, topSubmittersIsMergedOnly, topSubmittersMinPRs, topSubmittersLimit)
+
+	header := []string{"rank", "user", "PRs", "merged_PRs", "repositories", "first_month", "last_month"}
+	var rows [][]string
+	for _, submitter := range ranking {
+		rows = append(rows, []string{
+			strconv.Itoa(submitter.rank),
+			submitter.user,
+			strconv.Itoa(submitter.prs),
+			strconv.Itoa(submitter.mergedPRs),
+			strconv.Itoa(submitter.repositories),
+			submitter.firstMonth,
+			submitter.lastMonth,
+		})
+	}
+
+	outputFileName = computeReportOutputFileName(outputFileName, "top-submitters", fromMonth, toMonth, format)
+	if err := writeTableOutput(outputFileName, format, header, []string{"rank", "PRs", "merged_PRs", "repositories"}, rows); err != nil {
+		return err
+	}
+
+	if isVerbose {
+		fmt.Fprintf(os.Stderr, "Ranked %d submitters (%d PRs from %s to %s) in \"%s\"\n", len(ranking), len(submissions), fromMonth, toMonth, outputFileName)
+	}
+	return nil
+}
+
+// Ranks the submitters by decreasing number of PRs (or merged PRs).
+// Ties share the same rank and are sorted by GitHub handle (case insensitive).
+func rankSubmitters(submissions []submissionRecord, isMergedOnly bool, minPRs int, limit int) []rankedSubmitter {
+	submitterIndex := make(map[string]int)
+	repositories := make(map[string]map[string]bool)
+	var submitters []rankedSubmitter
+
+	for _, submission := range submissions {
+		i, exists := submitterIndex[submission.user]
+		if !exists {
+			i = len(submitters)
+			submitterIndex[submission.user] = i
+			submitters = append(submitters, rankedSubmitter{user: submission.user, firstMonth: submission.month, lastMonth: submission.month})
+			repositories[submission.user] = make(map[string]bool)
+		}
+		submitters[i].prs++
+		if submission.isMerged() {
+			submitters[i].mergedPRs++
+		}
+		repositories[submission.user][submission.repositorySpec()] = true
+		if submission.month < submitters[i].firstMonth {
+			submitters[i].firstMonth = submission.month
+		}
+		if submission.month > submitters[i].lastMonth {
+			submitters[i].lastMonth = submission.month
+		}
+	}
+
+	score := func(submitter rankedSubmitter) int {
+		if isMergedOnly {
+			return submitter.mergedPRs
+		}
+		return submitter.prs
+	}
+
+	var ranking []rankedSubmitter
+	for _, submitter := range submitters {
+		submitter.repositories = len(repositories[submitter.user])
+		if score(submitter) >= minPRs && score(submitter) > 0 {
+			ranking = append(ranking, submitter)
+		}
+	}
+
+	sort.SliceStable(ranking, func(i, j int) bool {
+		if score(ranking[i]) != score(ranking[j]) {
+			return score(ranking[i]) > score(ranking[j])
+		}
+		if !strings.EqualFold(ranking[i].user, ranking[j].user) {
+			return strings.ToLower(ranking[i].user) < strings.ToLower(ranking[j].user)
+		}
+		return ranking[i].user < ranking[j].user
+	})
+
+	for i := range ranking {
+		if i > 0 && score(ranking[i]) == score(ranking[i-1]) {
+			ranking[i].rank = ranking[i-1].rank
+		} else {
+			ranking[i].rank = i + 1
+		}
+		// Stop after the limit, but keep the ones sharing the last rank
+		if limit > 0 && i >= limit && ranking[i].rank != ranking[limit-1].rank {
+			return ranking[:i]
+		}
+	}
+	return ranking
+}
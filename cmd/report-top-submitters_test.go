@@ -0,0 +1,96 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_rankSubmitters(t *testing.T) {
+	submissions, err := loadSubmissionsForPeriod("../test-data/monthly", "2024-01", "2024-03", nil)
+	assert.NoError(t, err)
+
+	type ranked struct {
+		rank int
+		user string
+	}
+	tests := []struct {
+		name         string
+		isMergedOnly bool
+		minPRs       int
+		limit        int
+		want         []ranked
+	}{
+		{"all submitters", false, 1, 0, []ranked{{1, "alice"}, {1, "bob"}, {3, "dave"}, {4, "carol"}, {4, "erin"}}},
+		{"merged only", true, 1, 0, []ranked{{1, "bob"}, {2, "alice"}, {2, "dave"}, {4, "carol"}}},
+		{"minimum PRs", false, 2, 0, []ranked{{1, "alice"}, {1, "bob"}, {3, "dave"}}},
+		{"limit keeps ties", false, 1, 4, []ranked{{1, "alice"}, {1, "bob"}, {3, "dave"}, {4, "carol"}, {4, "erin"}}},
+		{"limit", false, 1, 3, []ranked{{1, "alice"}, {1, "bob"}, {3, "dave"}}},
+		{"limit in a tie", false, 1, 1, []ranked{{1, "alice"}, {1, "bob"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []ranked
+			for _, submitter := range rankSubmitters(submissions, tt.isMergedOnly, tt.minPRs, tt.limit) {
+				got = append(got, ranked{submitter.rank, submitter.user})
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_reportTopSubmittersCommand_integrationTest(t *testing.T) {
+	outputFileName := filepath.Join(t.TempDir(), "top.csv")
+
+	actual := new(bytes.Buffer)
+	rootCmd.SetOut(actual)
+	rootCmd.SetErr(actual)
+	rootCmd.SetArgs([]string{"report", "top-submitters", "--data_dir=../test-data/monthly", "--from=2024-01", "--to=2024-03",
+		"--format=csv", "--out=" + outputFileName, "--top=2", "--min_prs=1"})
+
+	error := rootCmd.Execute()
+
+	assert.NoError(t, error, "Call should not have failed")
+	content, err := os.ReadFile(outputFileName)
+	assert.NoError(t, err, "Report should have been generated")
+	assert.Equal(t, "rank,user,PRs,merged_PRs,repositories,first_month,last_month\n"+
+		"1,\"alice\",4,2,2,\"2024-01\",\"2024-02\"\n"+
+		"1,\"bob\",4,4,1,\"2024-01\",\"2024-03\"\n", string(content))
+}
+
+func Test_reportTopSubmittersCommand_invalidFormat(t *testing.T) {
+	actual := new(bytes.Buffer)
+	rootCmd.SetOut(actual)
+	rootCmd.SetErr(actual)
+	rootCmd.SetArgs([]string{"report", "top-submitters", "--data_dir=../test-data/monthly", "--format=xml"})
+
+	error := rootCmd.Execute()
+
+	assert.ErrorContains(t, error, "Unsupported output format")
+	// restore the default for the other tests
+	reportFormat = formatCSV
+}
@@ -0,0 +1,204 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var trendsWindow int
+
+// The monthly series of the trends report
+var trendMetrics = []string{"PRs", "merged_PRs", "submitters", "commenters", "new_contributors"}
+
+// reportTrendsCmd represents the "report trends" command
+var reportTrendsCmd = &cobra.Command{
+	Use:   "trends",
+	Short: "Computes the month-over-month trends",
+	Long: `Computes, for each month of the reported period, the number of PRs, merged PRs,
+submitters, commenters and new contributors (first-ever PR in the data directory).
+
+For each series, the report gives the difference with the previous month ("_delta") and
+the rolling average over the last "--window" months ("_avg"). The months before the
+reported period are used for the first deltas and averages (a month without data file
+counts as no activity).
+
+If not specified, the output file is "trends_<from>_<to>.<format>".`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if trendsWindow < 1 {
+			return fmt.Errorf("The rolling average window should be at least 1 month (found %d)", trendsWindow)
+		}
+		excludedUsers, err := validateReportParameters()
+		if err != nil {
+			return err
+		}
+		return performTrendsReport(reportDataDir, reportFromMonth, reportToMonth, reportOutputFileName, reportFormat, excludedUsers, trendsWindow)
+	},
+}
+
+func init() {
+	reportCmd.AddCommand(reportTrendsCmd)
+
+	reportTrendsCmd.Flags().IntVarP(&trendsWindow, "window", "", 3, "Number of months of the rolling average")
+}
+
+// Main function of the "report trends" command
+func performTrendsReport(dataDir string, fromMonth string, toMonth string, outputFileName string, format string, excludedUsers []string, window int) error {
+	fromMonth, toMonth, err := computeReportPeriod(dataDir, fromMonth, toMonth)
+	if err != nil {
+		return err
+	}
+
+	// The previous months are needed for the first deltas and rolling averages
+	nbrOfPreviousMonths := window - 1
+	if nbrOfPreviousMonths < 1 {
+		nbrOfPreviousMonths = 1
+	}
+	months := monthsOfPeriod(addMonths(fromMonth, -nbrOfPreviousMonths), toMonth)
+
+	history, err := loadSubmissionsHistory(dataDir, toMonth, excludedUsers)
+	if err != nil {
+		return err
+	}
+	comments, err := loadCommentsForPeriod(dataDir, months[0], toMonth, excludedUsers)
+	if err != nil {
+		return err
+	}
+
+	series := computeMonthlySeries(history, comments, months)
+
+	header := []string{"month"}
+	var numericColumns []string
+	for _, metric := range trendMetrics {
+		header = append(header, metric, metric+"_delta", metric+"_avg")
+		numericColumns = append(numericColumns, metric, metric+"_delta", metric+"_avg")
+	}
+
+	deltas := make(map[string][]int)
+	averages := make(map[string][]float64)
+	for _, metric := range trendMetrics {
+		deltas[metric] = computeDeltas(series[metric])
+		averages[metric] = computeRollingAverages(series[metric], window)
+	}
+
+	var rows [][]string
+	for i := nbrOfPreviousMonths; i < len(months); i++ {
+		row := []string{months[i]}
+		for _, metric := range trendMetrics {
+			row = append(row,
+				strconv.Itoa(series[metric][i]),
+				strconv.Itoa(deltas[metric][i]),
+				strconv.FormatFloat(averages[metric][i], 'f', 1, 64))
+		}
+		rows = append(rows, row)
+	}
+
+	outputFileName = computeReportOutputFileName(outputFileName, "trends", fromMonth, toMonth, format)
+	if err := writeTableOutput(outputFileName, format, header, numericColumns, rows); err != nil {
+		return err
+	}
+
+	if isVerbose {
+		fmt.Fprintf(os.Stderr, "Trends from %s to %s written to \"%s\"\n", fromMonth, toMonth, outputFileName)
+	}
+	return nil
+}
+
+// Computes the value of each trend metric for each of the months.
+// The submissions must contain the whole history (to identify the new contributors).
+func computeMonthlySeries(submissions []submissionRecord, comments []commentRecord, months []string) map[string][]int {
+	monthIndex := make(map[string]int)
+	for i, month := range months {
+		monthIndex[month] = i
+	}
+
+	series := make(map[string][]int)
+	for _, metric := range trendMetrics {
+		series[metric] = make([]int, len(months))
+	}
+
+	submitters := make([]map[string]bool, len(months))
+	commenters := make([]map[string]bool, len(months))
+	for i := range months {
+		submitters[i] = make(map[string]bool)
+		commenters[i] = make(map[string]bool)
+	}
+
+	for _, submission := range submissions {
+		i, exists := monthIndex[submission.month]
+		if !exists {
+			continue
+		}
+		series["PRs"][i]++
+		if submission.isMerged() {
+			series["merged_PRs"][i]++
+		}
+		submitters[i][submission.user] = true
+	}
+	for _, comment := range comments {
+		if i, exists := monthIndex[comment.month]; exists {
+			commenters[i][comment.commenter] = true
+		}
+	}
+	for _, first := range computeFirstContributions(submissions) {
+		if i, exists := monthIndex[first.month]; exists {
+			series["new_contributors"][i]++
+		}
+	}
+
+	for i := range months {
+		series["submitters"][i] = len(submitters[i])
+		series["commenters"][i] = len(commenters[i])
+	}
+	return series
+}
+
+// Computes the difference of each value with the previous one (0 for the first value)
+func computeDeltas(values []int) []int {
+	deltas := make([]int, len(values))
+	for i := 1; i < len(values); i++ {
+		deltas[i] = values[i] - values[i-1]
+	}
+	return deltas
+}
+
+// Computes the average of each value with the previous ones, over the window (shorter at the start)
+func computeRollingAverages(values []int, window int) []float64 {
+	averages := make([]float64, len(values))
+	sum := 0
+	for i, value := range values {
+		sum += value
+		if i >= window {
+			sum -= values[i-window]
+		}
+		nbrOfValues := window
+		if i+1 < window {
+			nbrOfValues = i + 1
+		}
+		averages[i] = float64(sum) / float64(nbrOfValues)
+	}
+	return averages
+}
@@ -0,0 +1,94 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_computeDeltas(t *testing.T) {
+	assert.Equal(t, []int{0, -2, 1}, computeDeltas([]int{5, 3, 4}))
+	assert.Equal(t, []int{}, computeDeltas([]int{}))
+}
+
+func Test_computeRollingAverages(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []int
+		window int
+		want   []float64
+	}{
+		{"window of 1", []int{5, 3, 4}, 1, []float64{5, 3, 4}},
+		{"window of 2", []int{5, 3, 4}, 2, []float64{5, 4, 3.5}},
+		{"window larger than the values", []int{5, 3, 4}, 6, []float64{5, 4, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, computeRollingAverages(tt.values, tt.window))
+		})
+	}
+}
+
+func Test_computeMonthlySeries(t *testing.T) {
+	history, err := loadSubmissionsHistory("../test-data/monthly", "2024-03", nil)
+	assert.NoError(t, err)
+	comments, err := loadCommentsForPeriod("../test-data/monthly", "2024-02", "2024-03", nil)
+	assert.NoError(t, err)
+
+	got := computeMonthlySeries(history, comments, []string{"2024-02", "2024-03", "2024-04"})
+
+	assert.Equal(t, map[string][]int{
+		"PRs":              {3, 4, 0},
+		"merged_PRs":       {3, 3, 0},
+		"submitters":       {3, 3, 0},
+		"commenters":       {2, 3, 0},
+		"new_contributors": {1, 1, 0},
+	}, got)
+}
+
+func Test_reportTrendsCommand_integrationTest(t *testing.T) {
+	outputFileName := filepath.Join(t.TempDir(), "trends.csv")
+
+	actual := new(bytes.Buffer)
+	rootCmd.SetOut(actual)
+	rootCmd.SetErr(actual)
+	rootCmd.SetArgs([]string{"report", "trends", "--data_dir=../test-data/monthly", "--from=2024-02", "--to=2024-03",
+		"--format=csv", "--out=" + outputFileName, "--window=2"})
+
+	error := rootCmd.Execute()
+	// restore the default for the other tests
+	trendsWindow = 3
+
+	assert.NoError(t, error, "Call should not have failed")
+	content, err := os.ReadFile(outputFileName)
+	assert.NoError(t, err, "Report should have been generated")
+	assert.Equal(t, "month,PRs,PRs_delta,PRs_avg,merged_PRs,merged_PRs_delta,merged_PRs_avg,"+
+		"submitters,submitters_delta,submitters_avg,commenters,commenters_delta,commenters_avg,"+
+		"new_contributors,new_contributors_delta,new_contributors_avg\n"+
+		"\"2024-02\",3,-2,\"4.0\",3,0,\"3.0\",3,0,\"3.0\",2,-1,\"2.5\",1,-2,\"2.0\"\n"+
+		"\"2024-03\",4,1,\"3.5\",3,0,\"3.0\",3,0,\"3.0\",3,1,\"2.5\",1,0,\"1.0\"\n", string(content))
+}
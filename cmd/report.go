@@ -0,0 +1,283 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var reportDataDir string
+var reportFromMonth string
+var reportToMonth string
+var reportOutputFileName string
+var reportFormat string
+var reportExcludeFileName string
+
+// reportCmd represents the report command
+var reportCmd = &cobra.Command{
+	Use:   "report [top-submitters|new-contributors|cohorts|lifecycle|repos|concentration|trends|affiliations]",
+	Short: "Generates community health reports from the extracted data",
+	Long: `Generates reports from the monthly data files stored in a data directory.
+
+The data directory is expected to contain the files generated by the "get" commands,
+named after the month they cover:
+- "submissions-YYYY-MM.csv" (generated by "get submitters")
+- "commenters-YYYY-MM.csv" (generated by "get commenters")
+
+The reported period is defined with "--from" and "--to" (both inclusive). If not
+specified, "--to" is the latest month available in the data directory and "--from"
+is 11 months earlier (a period of a year).
+`,
+}
+
+// Cobra initialize
+func init() {
+	rootCmd.AddCommand(reportCmd)
+
+	reportCmd.PersistentFlags().StringVarP(&reportDataDir, "data_dir", "", "data", "Directory containing the monthly data files")
+	reportCmd.PersistentFlags().StringVarP(&reportFromMonth, "from", "", "", "First month of the reported period (YYYY-MM)")
+	reportCmd.PersistentFlags().StringVarP(&reportToMonth, "to", "", "", "Last month of the reported period (YYYY-MM)")
+	reportCmd.PersistentFlags().StringVarP(&reportOutputFileName, "out", "o", "", "Output file name (\"-\" to write to the standard output). Default depends on the report.")
+	reportCmd.PersistentFlags().StringVarP(&reportFormat, "format", "f", formatCSV, "Output format (csv, md or json)")
+	reportCmd.PersistentFlags().StringVarP(&reportExcludeFileName, "excludeFile", "x", "", "Name of the file containing the github handles to exclude from the report.")
+}
+
+// How some reports group the data
+const (
+	groupByOrg  = "org"
+	groupByRepo = "repo"
+)
+
+// Validates the common report parameters and loads the exclusion list (if any)
+func validateReportParameters() ([]string, error) {
+	if !isValidDir(reportDataDir) {
+		return nil, fmt.Errorf("Supplied DataDir \"%s\" does not exist.", reportDataDir)
+	}
+	if reportFromMonth != "" && !isValidMonthFormat(reportFromMonth) {
+		return nil, fmt.Errorf("\"%s\" is not a valid month.", reportFromMonth)
+	}
+	if reportToMonth != "" && !isValidMonthFormat(reportToMonth) {
+		return nil, fmt.Errorf("\"%s\" is not a valid month.", reportToMonth)
+	}
+	if err := validateOutputFormat(reportFormat, []string{formatCSV, formatMarkdown, formatJSON}); err != nil {
+		return nil, err
+	}
+
+	var excludedUsers []string
+	if reportExcludeFileName != "" {
+		var err error
+		err, excludedUsers = load_exclusions(reportExcludeFileName)
+		if err != nil {
+			return nil, fmt.Errorf("invalid excluded user list => %v\n", err)
+		}
+	}
+	return excludedUsers, nil
+}
+
+// Computes the reported period. Missing bounds are computed from the available submissions files.
+func computeReportPeriod(dataDir string, fromMonth string, toMonth string) (string, string, error) {
+	if toMonth == "" {
+		availableMonths, err := listAvailableMonths(dataDir, "submissions")
+		if err != nil {
+			return "", "", err
+		}
+		if len(availableMonths) == 0 {
+			return "", "", fmt.Errorf("No submissions file found in \"%s\"", dataDir)
+		}
+		toMonth = availableMonths[len(availableMonths)-1]
+	}
+	if fromMonth == "" {
+		fromMonth = addMonths(toMonth, -11)
+	}
+	if fromMonth > toMonth {
+		return "", "", fmt.Errorf("Invalid period: %s is after %s", fromMonth, toMonth)
+	}
+	return fromMonth, toMonth, nil
+}
+
+// Computes the default output file name of a report ("<report>_<from>_<to>.<format>")
+func computeReportOutputFileName(suppliedFileName string, reportName string, fromMonth string, toMonth string, format string) string {
+	if suppliedFileName != "" {
+		return suppliedFileName
+	}
+	return fmt.Sprintf("%s_%s_%s%s", reportName, fromMonth, toMonth, outputFormatExtension(format))
+}
+
+// Returns the sorted list of months for which a "<prefix>-YYYY-MM.csv" file exists in the data directory
+func listAvailableMonths(dataDir string, prefix string) ([]string, error) {
+	fileList, err := filepath.Glob(filepath.Join(dataDir, prefix+"-*.csv"))
+	if err != nil {
+		return nil, err
+	}
+
+	var months []string
+	for _, fileName := range fileList {
+		matches := monthlyDataFile_regexp.FindStringSubmatch(filepath.Base(fileName))
+		if matches == nil || filepath.Base(fileName) != prefix+"-"+matches[1]+".csv" {
+			continue
+		}
+		months = append(months, matches[1])
+	}
+	sort.Strings(months)
+	return months, nil
+}
+
+// Returns the "<prefix>-YYYY-MM.csv" files of the data directory within the period (inclusive)
+func listMonthlyDataFiles(dataDir string, prefix string, fromMonth string, toMonth string) ([]string, error) {
+	availableMonths, err := listAvailableMonths(dataDir, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var fileList []string
+	for _, month := range availableMonths {
+		if month >= fromMonth && month <= toMonth {
+			fileList = append(fileList, filepath.Join(dataDir, prefix+"-"+month+".csv"))
+		}
+	}
+	return fileList, nil
+}
+
+// Loads the submissions of the data directory created within the period, skipping the excluded users
+func loadSubmissionsForPeriod(dataDir string, fromMonth string, toMonth string, excludedUsers []string) ([]submissionRecord, error) {
+	fileList, err := listMonthlyDataFiles(dataDir, "submissions", fromMonth, toMonth)
+	if err != nil {
+		return nil, err
+	}
+	if len(fileList) == 0 {
+		return nil, fmt.Errorf("No submissions file found in \"%s\" between %s and %s", dataDir, fromMonth, toMonth)
+	}
+
+	var submissions []submissionRecord
+	for _, fileName := range fileList {
+		if isVerbose {
+			fmt.Fprintf(os.Stderr, "Loading \"%s\"\n", fileName)
+		}
+		loaded, err := loadSubmissions(fileName)
+		if err != nil {
+			return nil, err
+		}
+		for _, submission := range loaded {
+			// the file name and the content could disagree: the content wins
+			if submission.month < fromMonth || submission.month > toMonth {
+				continue
+			}
+			if submission.user == "" || isExcludedAuthor(excludedUsers, submission.user) {
+				continue
+			}
+			submissions = append(submissions, submission)
+		}
+	}
+	return submissions, nil
+}
+
+// Loads all the submissions of the data directory up to the given month (included), skipping the excluded users.
+// This is the contribution history used to know whether a contributor is new.
+func loadSubmissionsHistory(dataDir string, toMonth string, excludedUsers []string) ([]submissionRecord, error) {
+	availableMonths, err := listAvailableMonths(dataDir, "submissions")
+	if err != nil {
+		return nil, err
+	}
+	if len(availableMonths) == 0 {
+		return nil, fmt.Errorf("No submissions file found in \"%s\"", dataDir)
+	}
+	return loadSubmissionsForPeriod(dataDir, availableMonths[0], toMonth, excludedUsers)
+}
+
+// Loads the comments of the data directory made within the period, skipping the excluded users.
+// Having no commenters file is not an error (the commenters are not always extracted).
+func loadCommentsForPeriod(dataDir string, fromMonth string, toMonth string, excludedUsers []string) ([]commentRecord, error) {
+	fileList, err := listMonthlyDataFiles(dataDir, "commenters", fromMonth, toMonth)
+	if err != nil {
+		return nil, err
+	}
+
+	var comments []commentRecord
+	for _, fileName := range fileList {
+		if isVerbose {
+			fmt.Fprintf(os.Stderr, "Loading \"%s\"\n", fileName)
+		}
+		loaded, err := loadComments(fileName)
+		if err != nil {
+			return nil, err
+		}
+		for _, comment := range loaded {
+			if comment.month < fromMonth || comment.month > toMonth {
+				continue
+			}
+			if comment.commenter == "" || isExcludedAuthor(excludedUsers, comment.commenter) {
+				continue
+			}
+			comments = append(comments, comment)
+		}
+	}
+	return comments, nil
+}
+
+// Returns all the months of the period (inclusive)
+func monthsOfPeriod(fromMonth string, toMonth string) []string {
+	var months []string
+	for month := fromMonth; month != "" && month <= toMonth; month = addMonths(month, 1) {
+		months = append(months, month)
+	}
+	return months
+}
+
+// Adds (or subtracts) a number of months to a month (YYYY-MM)
+func addMonths(month string, nbrOfMonths int) string {
+	monthDate, err := time.Parse("2006-01", month)
+	if err != nil {
+		return ""
+	}
+	return monthDate.AddDate(0, nbrOfMonths, 0).Format("2006-01")
+}
+
+// Formats the share of a total as a percentage with one decimal ("0" if the total is 0)
+func formatPercentage(part int, total int) string {
+	if total == 0 {
+		return "0"
+	}
+	return strconv.FormatFloat(float64(part)*100/float64(total), 'f', 1, 64)
+}
+
+// Checks that the grouping is either by organization or by repository
+func validateGroupBy(groupBy string) error {
+	if groupBy != groupByOrg && groupBy != groupByRepo {
+		return fmt.Errorf("Invalid grouping \"%s\" (should be \"%s\" or \"%s\")", groupBy, groupByOrg, groupByRepo)
+	}
+	return nil
+}
+
+// Returns the group ("org" or "org/repository") of a PR
+func computeGroup(org string, repository string, groupBy string) string {
+	if groupBy == groupByRepo {
+		return org + "/" + repository
+	}
+	return org
+}
@@ -0,0 +1,148 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_addMonths(t *testing.T) {
+	tests := []struct {
+		month       string
+		nbrOfMonths int
+		want        string
+	}{
+		{"2024-01", 1, "2024-02"},
+		{"2024-12", 1, "2025-01"},
+		{"2024-03", -11, "2023-04"},
+		{"2024-01", 0, "2024-01"},
+		{"junk", 1, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.month, func(t *testing.T) {
+			if got := addMonths(tt.month, tt.nbrOfMonths); got != tt.want {
+				t.Errorf("addMonths() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_listAvailableMonths(t *testing.T) {
+	got, err := listAvailableMonths("../test-data/monthly", "submissions")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2024-01", "2024-02", "2024-03"}, got)
+
+	// "submissions-2023-08_cleaned.csv" must not be taken into account
+	got, err = listAvailableMonths("../test-data", "submissions")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2023-02", "2023-03", "2023-04", "2023-05", "2023-06", "2023-07", "2023-08"}, got)
+}
+
+func Test_listMonthlyDataFiles(t *testing.T) {
+	got, err := listMonthlyDataFiles("../test-data/monthly", "commenters", "2024-02", "2024-05")
+	assert.NoError(t, err)
+	want := []string{
+		filepath.Join("../test-data/monthly", "commenters-2024-02.csv"),
+		filepath.Join("../test-data/monthly", "commenters-2024-03.csv"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("listMonthlyDataFiles() = %v, want %v", got, want)
+	}
+}
+
+func Test_computeReportPeriod(t *testing.T) {
+	tests := []struct {
+		name      string
+		fromMonth string
+		toMonth   string
+		wantFrom  string
+		wantTo    string
+		wantErr   bool
+	}{
+		{"defaults", "", "", "2023-04", "2024-03", false},
+		{"only from", "2024-02", "", "2024-02", "2024-03", false},
+		{"only to", "", "2024-02", "2023-03", "2024-02", false},
+		{"inverted period", "2024-03", "2024-01", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotFrom, gotTo, err := computeReportPeriod("../test-data/monthly", tt.fromMonth, tt.toMonth)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("computeReportPeriod() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.wantFrom, gotFrom)
+			assert.Equal(t, tt.wantTo, gotTo)
+		})
+	}
+
+	_, _, err := computeReportPeriod(t.TempDir(), "", "")
+	assert.Error(t, err, "Empty data directory should fail")
+}
+
+func Test_computeReportOutputFileName(t *testing.T) {
+	assert.Equal(t, "top-submitters_2024-01_2024-03.csv", computeReportOutputFileName("", "top-submitters", "2024-01", "2024-03", formatCSV))
+	assert.Equal(t, "top-submitters_2024-01_2024-03.md", computeReportOutputFileName("", "top-submitters", "2024-01", "2024-03", formatMarkdown))
+	assert.Equal(t, "report.txt", computeReportOutputFileName("report.txt", "top-submitters", "2024-01", "2024-03", formatCSV))
+}
+
+func Test_loadSubmissionsForPeriod(t *testing.T) {
+	got, err := loadSubmissionsForPeriod("../test-data/monthly", "2024-02", "2024-03", []string{"BOB"})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(got), "bob's PRs should have been excluded")
+
+	_, err = loadSubmissionsForPeriod("../test-data/monthly", "2023-01", "2023-03", nil)
+	assert.Error(t, err, "Period without data should fail")
+}
+
+func Test_loadCommentsForPeriod(t *testing.T) {
+	comments, err := loadCommentsForPeriod("../test-data/monthly", "2024-02", "2024-03", []string{"frank"})
+	assert.NoError(t, err)
+	assert.Len(t, comments, 5)
+
+	comments, err = loadCommentsForPeriod("../test-data", "2024-02", "2024-03", nil)
+	assert.NoError(t, err, "a missing commenters file is not an error")
+	assert.Empty(t, comments)
+}
+
+func Test_monthsOfPeriod(t *testing.T) {
+	assert.Equal(t, []string{"2023-11", "2023-12", "2024-01"}, monthsOfPeriod("2023-11", "2024-01"))
+	assert.Equal(t, []string{"2024-01"}, monthsOfPeriod("2024-01", "2024-01"))
+	assert.Nil(t, monthsOfPeriod("2024-02", "2024-01"))
+}
+
+func Test_formatPercentage(t *testing.T) {
+	assert.Equal(t, "66.7", formatPercentage(2, 3))
+	assert.Equal(t, "100.0", formatPercentage(3, 3))
+	assert.Equal(t, "0", formatPercentage(0, 0))
+}
+
+func Test_computeGroup(t *testing.T) {
+	assert.Equal(t, "jenkinsci", computeGroup("jenkinsci", "jenkins", groupByOrg))
+	assert.Equal(t, "jenkinsci/jenkins", computeGroup("jenkinsci", "jenkins", groupByRepo))
+	assert.NoError(t, validateGroupBy(groupByRepo))
+	assert.Error(t, validateGroupBy("user"))
+}
@@ -37,6 +37,7 @@ var isRootDebug bool
 var globalIsAppend bool
 var globalIsNoHeader bool
 var globalIsBigFile bool
+var aliasFileName string
 
 // if an exclusion file is available, will contain the list of users to exclude
 var excludedGithubUsers []string
@@ -49,6 +50,18 @@ var rootCmd = &cobra.Command{
 It currently gets data about Pull Request submitters and commenters on those Pull Requests.
 `,
 	SilenceUsage: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// The identities are merged by all the commands
+		userAliases = identityAliases{}
+		if aliasFileName != "" {
+			var err error
+			userAliases, err = loadIdentityAliases(aliasFileName)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -65,6 +78,7 @@ func init() {
 
 	rootCmd.PersistentFlags().StringVarP(&ghTokenVar, "token_var", "t", "GITHUB_TOKEN", "The environment variable containing the GitHub token.")
 	rootCmd.PersistentFlags().BoolVarP(&isVerbose, "verbose", "v", false, "Displays useful info during the extraction.")
+	rootCmd.PersistentFlags().StringVarP(&aliasFileName, "aliases", "", "", "File mapping the different logins of a person to a single one (mailmap-like).")
 
 	//Disable the Cobra completion options
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
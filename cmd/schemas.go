@@ -0,0 +1,307 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// The kinds of data files produced or consumed by the tool
+const (
+	schemaSubmitters     = "submitters"
+	schemaCommenters     = "commenters"
+	schemaPrPerSubmitter = "pr_per_submitter"
+	schemaPrPerRepo      = "pr_per_submitter_per_repo"
+	schemaCommentsCount  = "comments_per_commenter"
+	schemaHonor          = "honored_contributor"
+	schemaUserProfiles   = "user_profiles"
+)
+
+// Describes the layout of a CSV data file at a given version
+type csvSchema struct {
+	kind         string
+	version      int
+	columns      []string
+	separator    string
+	quotedHeader bool
+}
+
+// All the known data file layouts.
+// An existing entry must never be modified: when a layout changes, a new version is added.
+// The "migrate" command upgrades data files to the latest version of their kind.
+var csvSchemaRegistry = []csvSchema{
+	{
+		kind:      schemaSubmitters,
+		version:   1,
+		columns:   []string{"org", "repository", "number", "url", "state", "created_at", "merged_at", "user.login", "month_year", "title"},
+		separator: ",",
+	},
+	// The first response and approval are searched in the first 20 comments and reviews of the PR
+	// (empty if none of them qualifies)
+	{
+		kind:    schemaSubmitters,
+		version: 2,
+		columns: []string{"org", "repository", "number", "url", "state", "created_at", "merged_at", "user.login", "month_year", "title",
+			"closed_at", "first_response_at", "first_approval_at"},
+		separator: ",",
+	},
+	{
+		kind:      schemaCommenters,
+		version:   1,
+		columns:   []string{"PR_ref", "commenter", "month"},
+		separator: ",",
+	},
+	{
+		kind:      schemaPrPerSubmitter,
+		version:   1,
+		columns:   []string{"user", "PR"},
+		separator: ",",
+	},
+	{
+		kind:      schemaPrPerRepo,
+		version:   1,
+		columns:   []string{"user", "repository", "PR"},
+		separator: ",",
+	},
+	{
+		kind:      schemaCommentsCount,
+		version:   1,
+		columns:   []string{"commenter", "month", "comments", "PRs"},
+		separator: ",",
+	},
+	{
+		kind:         schemaHonor,
+		version:      1,
+		columns:      []string{"RUN_DATE", "MONTH", "GH_HANDLE", "FULL_NAME", "COMPANY", "GH_HANDLE_URL", "GH_HANDLE_AVATAR", "NBR_PR", "REPOSITORIES"},
+		separator:    ", ",
+		quotedHeader: true,
+	},
+	{
+		kind:         schemaHonor,
+		version:      2,
+		columns:      []string{"RUN_DATE", "MONTH", "GH_HANDLE", "FULL_NAME", "COMPANY", "GH_HANDLE_URL", "GH_HANDLE_AVATAR", "NBR_PR", "REPOSITORIES", "SEED"},
+		separator:    ", ",
+		quotedHeader: true,
+	},
+	{
+		kind:         schemaHonor,
+		version:      3,
+		columns:      []string{"RUN_DATE", "MONTH", "GH_HANDLE", "FULL_NAME", "COMPANY", "GH_HANDLE_URL", "GH_HANDLE_AVATAR", "NBR_PR", "REPOSITORIES", "SEED", "RANK", "REASON"},
+		separator:    ", ",
+		quotedHeader: true,
+	},
+	{
+		kind:      schemaUserProfiles,
+		version:   1,
+		columns:   []string{"login", "company", "fetched_at"},
+		separator: ",",
+	},
+	{
+		kind:      schemaUserProfiles,
+		version:   2,
+		columns:   []string{"login", "name", "company", "avatar_url", "url", "type", "fetched_at"},
+		separator: ",",
+	},
+}
+
+// Returns the latest version of the schema for the given kind
+func currentSchema(kind string) csvSchema {
+	var latest csvSchema
+	for _, schema := range csvSchemaRegistry {
+		if schema.kind == kind && schema.version > latest.version {
+			latest = schema
+		}
+	}
+	return latest
+}
+
+// Returns the header line as it is written by the tool
+func (s csvSchema) headerLine() string {
+	if s.quotedHeader {
+		return formatCSVrecord(s.columns, s.separator)
+	}
+	return strings.Join(s.columns, s.separator)
+}
+
+// Returns true if the schema is the latest version of its kind
+func (s csvSchema) isCurrent() bool {
+	return s.version == currentSchema(s.kind).version
+}
+
+// Identifies the schema (kind and version) of a data file based on its header.
+// Columns are matched by name (ignoring surrounding white spaces), whatever their order.
+// Unknown extra columns are tolerated. When several versions match, the most complete wins.
+func detectSchema(header []string) (csvSchema, error) {
+	available := make(map[string]bool)
+	for _, column := range header {
+		available[strings.TrimSpace(column)] = true
+	}
+
+	var found csvSchema
+	for _, schema := range csvSchemaRegistry {
+		isMatching := true
+		for _, column := range schema.columns {
+			if !available[column] {
+				isMatching = false
+				break
+			}
+		}
+		if isMatching && len(schema.columns) > len(found.columns) {
+			found = schema
+		}
+	}
+
+	if found.kind == "" {
+		return found, fmt.Errorf("Unrecognized header %s", prettyPrintStringList(header))
+	}
+	return found, nil
+}
+
+// A data file loaded in memory, with its columns accessible by name
+type csvTable struct {
+	schema      csvSchema
+	header      []string
+	columnIndex map[string]int
+	records     [][]string
+	lines       []int // line number in the file of each record
+}
+
+// Returns the value of the named column for the given record (empty if the column is not available)
+func (t csvTable) get(record []string, column string) string {
+	i, ok := t.columnIndex[column]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// Loads a CSV data file, identifying its schema.
+// If the file name is "-", the data is read from the standard input.
+func loadCSVtable(fileName string) (csvTable, error) {
+	if isStdStream(fileName) {
+		return readCSVtable(os.Stdin)
+	}
+
+	f, err := os.Open(fileName)
+	if err != nil {
+		return csvTable{}, fmt.Errorf("Unable to read input file %s: %v", fileName, err)
+	}
+	defer f.Close()
+
+	table, err := readCSVtable(f)
+	if err != nil {
+		return table, fmt.Errorf("Error loading \"%s\": %v", fileName, err)
+	}
+	return table, nil
+}
+
+// Reads CSV data, identifying its schema with the header line.
+// Records with a different number of fields than the header are accepted (they are checked by "validate").
+func readCSVtable(input io.Reader) (csvTable, error) {
+	var table csvTable
+
+	r := csv.NewReader(input)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		return table, fmt.Errorf("unable to read header (%v)", err)
+	}
+
+	schema, err := detectSchema(header)
+	if err != nil {
+		return table, err
+	}
+
+	var records [][]string
+	var lines []int
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return table, err
+		}
+		line, _ := r.FieldPos(0)
+		records = append(records, record)
+		lines = append(lines, line)
+	}
+
+	table.schema = schema
+	table.header = header
+	table.records = records
+	table.lines = lines
+	table.columnIndex = make(map[string]int)
+	for i, column := range header {
+		column = strings.TrimSpace(column)
+		// In case of duplicated columns, the first one wins
+		if _, exists := table.columnIndex[column]; !exists {
+			table.columnIndex[column] = i
+		}
+	}
+	return table, nil
+}
+
+// Converts the records of a loaded table to the latest version of its schema.
+// Columns are mapped by name: columns not available in the loaded file are left empty
+// and columns unknown to the schema are dropped.
+func migrateTable(table csvTable) (csvSchema, [][]string) {
+	target := currentSchema(table.schema.kind)
+
+	var migrated [][]string
+	for _, record := range table.records {
+		var newRecord []string
+		for _, column := range target.columns {
+			newRecord = append(newRecord, table.get(record, column))
+		}
+		migrated = append(migrated, newRecord)
+	}
+	return target, migrated
+}
+
+var integerField_regexp = regexp.MustCompile(`^-?[0-9]+$`)
+
+// Formats a record as a CSV line the way the tool writes its data:
+// every field is quoted, except integers.
+func formatCSVrecord(record []string, separator string) string {
+	var fields []string
+	for _, field := range record {
+		if integerField_regexp.MatchString(field) {
+			fields = append(fields, field)
+		} else {
+			fields = append(fields, quoteCSVfield(field))
+		}
+	}
+	return strings.Join(fields, separator)
+}
+
+// Quotes a CSV field, doubling the quotes it contains
+func quoteCSVfield(field string) string {
+	return "\"" + strings.ReplaceAll(field, "\"", "\"\"") + "\""
+}
@@ -0,0 +1,180 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_detectSchema(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      []string
+		wantKind    string
+		wantVersion int
+		wantErr     bool
+	}{
+		{
+			"submitters file",
+			[]string{"org", "repository", "number", "url", "state", "created_at", "merged_at", "user.login", "month_year", "title"},
+			schemaSubmitters, 1, false,
+		},
+		{
+			"submitters file with lifecycle dates",
+			[]string{"org", "repository", "number", "url", "state", "created_at", "merged_at", "user.login", "month_year", "title",
+				"closed_at", "first_response_at", "first_approval_at"},
+			schemaSubmitters, 2, false,
+		},
+		{
+			"commenters file",
+			[]string{"PR_ref", "commenter", "month"},
+			schemaCommenters, 1, false,
+		},
+		{
+			"pr_per_submitter file with junk column",
+			[]string{"user", "PR", " junk"},
+			schemaPrPerSubmitter, 1, false,
+		},
+		{
+			"pr_per_submitter file with columns in another order",
+			[]string{"PR", "user"},
+			schemaPrPerSubmitter, 1, false,
+		},
+		{
+			"honored contributor file",
+			[]string{"RUN_DATE", " MONTH", " GH_HANDLE", " FULL_NAME", " COMPANY", " GH_HANDLE_URL", " GH_HANDLE_AVATAR", " NBR_PR", " REPOSITORIES"},
+			schemaHonor, 1, false,
+		},
+		{
+			"honored contributor file with rank and reason",
+			[]string{"RUN_DATE", " MONTH", " GH_HANDLE", " FULL_NAME", " COMPANY", " GH_HANDLE_URL", " GH_HANDLE_AVATAR", " NBR_PR", " REPOSITORIES", " SEED", " RANK", " REASON"},
+			schemaHonor, 3, false,
+		},
+		{
+			"missing column",
+			[]string{"login", "PR"},
+			"", 0, true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := detectSchema(tt.header)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("detectSchema() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got.kind != tt.wantKind || got.version != tt.wantVersion {
+				t.Errorf("detectSchema() = %s v%d, want %s v%d", got.kind, got.version, tt.wantKind, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func Test_readCSVtable_columnsByName(t *testing.T) {
+	input := "PR, user, junk\n69,\"basil\",zzz\n40,\"gounthar\"\n"
+
+	table, err := readCSVtable(strings.NewReader(input))
+
+	assert.NoError(t, err, "Unexpected error reading table")
+	assert.Equal(t, schemaPrPerSubmitter, table.schema.kind)
+	assert.Equal(t, 2, len(table.records))
+	assert.Equal(t, "basil", table.get(table.records[0], "user"))
+	assert.Equal(t, "69", table.get(table.records[0], "PR"))
+	assert.Equal(t, "gounthar", table.get(table.records[1], "user"))
+	assert.Equal(t, "", table.get(table.records[1], "junk"), "Short record should return an empty value")
+	assert.Equal(t, "", table.get(table.records[1], "unknown"), "Unknown column should return an empty value")
+}
+
+func Test_loadCSVtable(t *testing.T) {
+	tests := []struct {
+		name        string
+		fileName    string
+		wantKind    string
+		wantRecords int
+		wantErr     bool
+	}{
+		{"submissions file", "../test-data/small-submission-list.csv", schemaSubmitters, 6, false},
+		{"pr_per_submitter with junk column", "../test-data/pr_per_submitter-2024-03.csv", schemaPrPerSubmitter, 185, false},
+		{"unrecognized header", "../test-data/pr_per_submitter-2024-02.csv", "", 0, true},
+		{"exclusion file", "../test-data/test-exclusion.txt", "", 0, true},
+		{"inexistent file", "../test-data/inexistent.csv", "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadCSVtable(tt.fileName)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("loadCSVtable() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got.schema.kind != tt.wantKind {
+				t.Errorf("loadCSVtable() kind = %v, want %v", got.schema.kind, tt.wantKind)
+			}
+			if len(got.records) != tt.wantRecords {
+				t.Errorf("loadCSVtable() records = %v, want %v", len(got.records), tt.wantRecords)
+			}
+		})
+	}
+}
+
+func Test_migrateTable(t *testing.T) {
+	input := "PR,user, junk\n69,\"basil\",zzz\n"
+	table, err := readCSVtable(strings.NewReader(input))
+	assert.NoError(t, err, "Unexpected error reading table")
+
+	gotSchema, gotRecords := migrateTable(table)
+
+	assert.Equal(t, currentSchema(schemaPrPerSubmitter), gotSchema)
+	if !reflect.DeepEqual(gotRecords, [][]string{{"basil", "69"}}) {
+		t.Errorf("migrateTable() = %v", gotRecords)
+	}
+}
+
+func Test_formatCSVrecord(t *testing.T) {
+	tests := []struct {
+		name      string
+		record    []string
+		separator string
+		want      string
+	}{
+		{"strings and integer", []string{"jenkinsci", "229", "Test with Java 21"}, ",", "\"jenkinsci\",229,\"Test with Java 21\""},
+		{"empty field", []string{"a", ""}, ",", "\"a\",\"\""},
+		{"embedded quote", []string{"say \"hi\""}, ",", "\"say \"\"hi\"\"\""},
+		{"honor separator", []string{"a", "b"}, ", ", "\"a\", \"b\""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatCSVrecord(tt.record, tt.separator); got != tt.want {
+				t.Errorf("formatCSVrecord() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_headerLine(t *testing.T) {
+	assert.Equal(t, "org,repository,number,url,state,created_at,merged_at,user.login,month_year,title,closed_at,first_response_at,first_approval_at", currentSchema(schemaSubmitters).headerLine())
+	assert.Equal(t, "PR_ref,commenter,month", currentSchema(schemaCommenters).headerLine())
+	assert.Equal(t, generateHonoredContributorDataCSVheader(), currentSchema(schemaHonor).headerLine(), "Honor header and schema are out of sync")
+}
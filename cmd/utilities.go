@@ -24,8 +24,10 @@ package cmd
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -63,8 +65,30 @@ func validatePRspec(prSpec string) (org string, project string, prNbr int, err e
 	return work_Org, work_Project, work_prNbr, nil
 }
 
+var prj_regexp = regexp.MustCompile(`^[\w-\.]+$`) // see https://stackoverflow.com/questions/59081778/rules-for-special-characters-in-github-repository-name
+var prNbr_regexp = regexp.MustCompile(`^\d+$`)
+
+// validates the org, project and PR number fields of a data record
+// (the elements of a PR specification stored in separate columns)
+func validatePRfields(org string, prj string, prNbr string) error {
+	if !isValidOrgFormat(org) {
+		return fmt.Errorf("ORG field \"%s\" doesn't seem to be a valid GitHub org.", org)
+	}
+
+	// project name must be "^[\w-\.]+$"
+	if !prj_regexp.MatchString(strings.ToLower(prj)) {
+		return fmt.Errorf("PRJ field \"%s\" is not of the expected format", prj)
+	}
+
+	// PR number must be a number
+	if !prNbr_regexp.MatchString(prNbr) {
+		return fmt.Errorf("PR field \"%s\" is not a (positive) number", prNbr)
+	}
+	return nil
+}
+
 // Write the string slice to a file formatted as a CSV
-func writeCSVtoFile(out *os.File, isAppend bool, isNoHeader bool, header string, csv_output_slice []string) {
+func writeCSVtoFile(out io.Writer, isAppend bool, isNoHeader bool, header string, csv_output_slice []string) {
 
 	localIsNoHeader := isNoHeader
 
@@ -87,13 +111,45 @@ func writeCSVtoFile(out *os.File, isAppend bool, isNoHeader bool, header string,
 	datawriter.Flush()
 }
 
+// The file name used to designate the standard input or output (for pipeline use)
+const stdStreamName = "-"
+
+// Returns true if the file name designates the standard input or output
+func isStdStream(fileName string) bool {
+	return fileName == stdStreamName
+}
+
+// Set as soon as data has been written to the standard output.
+// Further writes to the standard output are handled as an append to an existing file.
+var isStdoutUsed bool
+
+// Wraps the standard output so that closing it has no effect (it can be "opened" several times)
+type stdoutWriter struct {
+	io.Writer
+}
+
+func (stdoutWriter) Close() error {
+	return nil
+}
+
 // creates or opens for append (if the file exists) the output file
 // If no append is requested and the file exists, it is overwritten
-func openOutputCSV(outFname string, isAppend bool, isNoHeader bool) (*os.File, bool) {
+// If the file name is "-", the data is written to the standard output.
+func openOutputCSV(outFname string, isAppend bool, isNoHeader bool) (io.WriteCloser, bool, error) {
 
-	isExisting := fileExist(outputFileName)
 	localIsNoHeader := isNoHeader
 
+	if isStdStream(outFname) {
+		// Behaves like a file that is created at the first write
+		if isStdoutUsed && isAppend {
+			localIsNoHeader = true
+		}
+		isStdoutUsed = true
+		return stdoutWriter{os.Stdout}, localIsNoHeader, nil
+	}
+
+	isExisting := fileExist(outFname)
+
 	var isAppendString string
 	isNoHeaderString := "without"
 	if !localIsNoHeader {
@@ -108,7 +164,7 @@ func openOutputCSV(outFname string, isAppend bool, isNoHeader bool) (*os.File, b
 			// Open for append
 			out, open_error = os.OpenFile(outFname, os.O_APPEND|os.O_WRONLY, 0644)
 			if open_error != nil {
-				log.Fatal(open_error)
+				return nil, localIsNoHeader, fmt.Errorf("Unable to open \"%s\": %v", outFname, open_error)
 			}
 
 			isAppendString = "(appending"
@@ -119,7 +175,7 @@ func openOutputCSV(outFname string, isAppend bool, isNoHeader bool) (*os.File, b
 			// overwrite output file
 			out, open_error = os.Create(outFname)
 			if open_error != nil {
-				log.Fatal(open_error)
+				return nil, localIsNoHeader, fmt.Errorf("Unable to overwrite \"%s\": %v", outFname, open_error)
 			}
 			isAppendString = "(overwriting"
 			// honor the noheader setting
@@ -128,17 +184,41 @@ func openOutputCSV(outFname string, isAppend bool, isNoHeader bool) (*os.File, b
 		//create output file
 		out, open_error = os.Create(outFname)
 		if open_error != nil {
-			log.Fatal(open_error)
+			return nil, localIsNoHeader, fmt.Errorf("Unable to create \"%s\": %v", outFname, open_error)
 		}
 		isAppendString = "(creating"
 		// honor noHeader setting
 	}
 
 	if isVerbose {
-		fmt.Printf("Writing data to \"%s\" %s %s header)\n", outFname, isAppendString, isNoHeaderString)
+		fmt.Fprintf(os.Stderr, "Writing data to \"%s\" %s %s header)\n", outFname, isAppendString, isNoHeaderString)
 	}
 
-	return out, localIsNoHeader
+	return out, localIsNoHeader, nil
+}
+
+// Creates or overwrites the output file ("-" for the standard output) with the lines,
+// preceded by the header unless told otherwise
+func writeOutputFile(outFname string, isNoHeader bool, header string, lines []string) error {
+	out, isNoHeader, err := openOutputCSV(outFname, false, isNoHeader)
+	if err != nil {
+		return err
+	}
+	writeCSVtoFile(out, false, isNoHeader, header, lines)
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("Unable to write \"%s\": %v", outFname, err)
+	}
+	return nil
+}
+
+// Removes an existing output file so that it can be recreated (nothing to do for the standard output)
+func resetOutputFile(outFname string) {
+	if isStdStream(outFname) {
+		return
+	}
+	if fileExist(outFname) {
+		os.Remove(outFname)
+	}
 }
 
 // Validates that the input file is a real file (and not a directory)
@@ -154,7 +234,7 @@ func fileExist(fileName string) bool {
 func loadGitHubToken(envVariableName string) string {
 	token, found := os.LookupEnv(envVariableName)
 	if !found {
-		fmt.Println("Unauthorized: No token present")
+		fmt.Fprintln(os.Stderr, "Unauthorized: No token present")
 		//This is a major error: we crash out of the program
 		log.Fatal("GitHub token not found!")
 	}
@@ -196,7 +276,7 @@ func truncateString(input string, max int) (otput string) {
 func isValidMonthFormat(input string) bool {
 	if input == "" {
 		if isVerbose {
-			fmt.Print("Empty month\n")
+			fmt.Fprint(os.Stderr, "Empty month\n")
 		}
 		return false
 	}
@@ -204,7 +284,7 @@ func isValidMonthFormat(input string) bool {
 	regexpMonth := regexp.MustCompile(`^20[12][0-9]-(0[1-9]|1[0-2])$`)
 	if !regexpMonth.MatchString(input) {
 		if isVerbose {
-			fmt.Printf("Supplied data (%s) is not in a valid month format. Should be \"YYYY-MM\" and later than 2010\n", input)
+			fmt.Fprintf(os.Stderr, "Supplied data (%s) is not in a valid month format. Should be \"YYYY-MM\" and later than 2010\n", input)
 		}
 		return false
 	}
@@ -212,10 +292,11 @@ func isValidMonthFormat(input string) bool {
 }
 
 // Checks if an author is in the list of authors to exclude.
+// The comparison is case insensitive and takes the identity aliases into account.
 // This function assumes that supplied data has been checked upstream.
 func isExcludedAuthor(authorList []string, authorToCheck string) bool {
 	for _, author := range authorList {
-		if strings.EqualFold(author, authorToCheck) {
+		if userAliases.isSameIdentity(author, authorToCheck) {
 			return true
 		}
 	}
@@ -252,7 +333,7 @@ func prettyPrintStringList(listToPrint []string) string {
 func isValidOrgFormat(input string) bool {
 	if input == "" {
 		if isVerbose {
-			fmt.Print("Empty Org\n")
+			fmt.Fprint(os.Stderr, "Empty Org\n")
 		}
 		return false
 	}
@@ -263,7 +344,7 @@ func isValidOrgFormat(input string) bool {
 	name_regexp := regexp.MustCompile(`^[a-zA-Z0-9\-]+$`)
 	if !name_regexp.MatchString(input) {
 		if isVerbose {
-			fmt.Printf("Supplied data (%s) is not in a valid GitHub user/org format.\n", input)
+			fmt.Fprintf(os.Stderr, "Supplied data (%s) is not in a valid GitHub user/org format.\n", input)
 		}
 		return false
 	}
@@ -403,14 +484,14 @@ func getStartAndEndOfMonth(shortMonth string) (startDate string, endDate string)
 func validateHeader(header []string, referenceHeader []string, isVerbose bool) bool {
 	if len(header) != len(referenceHeader) {
 		if isVerbose {
-			fmt.Printf(" Error: field number mismatch (found %d, wanted %d)\n", len(header), len(referenceHeader))
+			fmt.Fprintf(os.Stderr, " Error: field number mismatch (found %d, wanted %d)\n", len(header), len(referenceHeader))
 		}
 		return false
 	}
 	for i, v := range header {
 		if v != referenceHeader[i] {
 			if isVerbose {
-				fmt.Printf(" Error: not the expected header field at column %d (found \"%v\", wanted \"%v\")\n", i+1, v, referenceHeader[i])
+				fmt.Fprintf(os.Stderr, " Error: not the expected header field at column %d (found \"%v\", wanted \"%v\")\n", i+1, v, referenceHeader[i])
 			}
 			return false
 		}
@@ -426,3 +507,18 @@ func isValidDir(dirName string) bool {
 	}
 	return info.IsDir()
 }
+
+// Based on a filename, will return a filename to store a backup.
+// The backup is stored next to the original file and is prefixed with the supplied prefix and a time stamp.
+func compute_backupFileName(fileName string, prefix string) string {
+	//The validity and existence of the data file are assumed to exist
+	//Compute the current backup timestamp "YYYYMMDD_HHMMSS" (to be prepend to the original file name)
+	dt := time.Now()
+	backupTimeStamp := fmt.Sprint(dt.Format("20060102_150405"))
+
+	shortFileName := filepath.Base(fileName)
+	path := filepath.Dir(fileName)
+	backup_FileName := fmt.Sprintf("%s/%s_%s__%s", path, prefix, backupTimeStamp, shortFileName)
+
+	return (backup_FileName)
+}
@@ -22,7 +22,11 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func Test_validatePRspec(t *testing.T) {
@@ -707,3 +711,46 @@ func Test_isValidDir(t *testing.T) {
 		})
 	}
 }
+
+func Test_openOutputCSV_stdout(t *testing.T) {
+	isStdoutUsed = false
+	defer func() { isStdoutUsed = false }()
+
+	out, isNoHeader, err := openOutputCSV("-", true, false)
+	assert.NoError(t, err)
+	assert.NoError(t, out.Close(), "Closing the standard output should have no effect")
+	assert.False(t, isNoHeader, "Header expected at the first write to the standard output")
+
+	_, isNoHeader, _ = openOutputCSV("-", true, false)
+	assert.True(t, isNoHeader, "No header expected when appending to the standard output")
+
+	_, isNoHeader, _ = openOutputCSV("-", false, false)
+	assert.False(t, isNoHeader, "Header expected when not appending")
+}
+
+func Test_openOutputCSV_unwritable(t *testing.T) {
+	_, _, err := openOutputCSV(filepath.Join(t.TempDir(), "missing_dir", "out.csv"), false, false)
+	assert.ErrorContains(t, err, "Unable to create")
+}
+
+func Test_writeOutputFile(t *testing.T) {
+	outputFileName := filepath.Join(t.TempDir(), "out.csv")
+	assert.NoError(t, writeOutputFile(outputFileName, false, "user,PR", []string{"\"basil\",69"}))
+	content, err := os.ReadFile(outputFileName)
+	assert.NoError(t, err)
+	assert.Equal(t, "user,PR\n\"basil\",69\n", string(content))
+
+	assert.Error(t, writeOutputFile(filepath.Join(t.TempDir(), "missing_dir", "out.csv"), false, "user,PR", nil))
+}
+
+func Test_resetOutputFile(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "output.csv")
+	assert.NoError(t, os.WriteFile(fileName, []byte("data\n"), 0644))
+
+	resetOutputFile(fileName)
+	assert.NoFileExists(t, fileName, "Output file should have been removed")
+
+	// Should not fail on the standard output or a missing file
+	resetOutputFile("-")
+	resetOutputFile(fileName)
+}
@@ -0,0 +1,358 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var validateOutput string
+var validateExcludeFileName string
+
+// Severity of a validation issue. Only errors make the validation fail.
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// A single problem found in a data file
+type validationIssue struct {
+	Line     int    `json:"line,omitempty"`
+	Severity string `json:"severity"`
+	Check    string `json:"check"`
+	Message  string `json:"message"`
+}
+
+// The validation result of a single data file
+type fileValidationReport struct {
+	File     string            `json:"file"`
+	Kind     string            `json:"kind"`
+	Version  int               `json:"version"`
+	Records  int               `json:"records"`
+	Errors   int               `json:"errors"`
+	Warnings int               `json:"warnings"`
+	Issues   []validationIssue `json:"issues"`
+}
+
+// The validation result of all the checked files
+type validationReport struct {
+	Valid    bool                   `json:"valid"`
+	Errors   int                    `json:"errors"`
+	Warnings int                    `json:"warnings"`
+	Files    []fileValidationReport `json:"files"`
+}
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate <file|dir>",
+	Short: "Checks data files before using them",
+	Long: `This command checks a data file, or all the CSV files of a data directory, before
+they are fed to other commands ("honor", "get commenters", etc.).
+
+The following checks are performed:
+- the kind of file and its schema version are detected with the header
+  (a warning is issued if the file should be migrated),
+- every record has the same number of fields as the header,
+- the PR references are syntactically correct,
+- the month of a submission is consistent with its creation date,
+- there are no duplicated records,
+- no user of the exclusion file (if supplied) is present.
+
+The result is a JSON report. The command exits with a non-zero status if errors are found.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if err := cobra.MinimumNArgs(1)(cmd, args); err != nil {
+			return err
+		}
+		if !fileExist(args[0]) && !isValidDir(args[0]) {
+			return fmt.Errorf("ERROR: %s is not an existing file or directory.\n", args[0])
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return performValidate(args[0], validateOutput, validateExcludeFileName)
+	},
+}
+
+// Initializes COBRA for this command
+func init() {
+	rootCmd.AddCommand(validateCmd)
+
+	validateCmd.Flags().StringVarP(&validateOutput, "out", "o", "", "File to write the report to (default: standard output)")
+	validateCmd.Flags().StringVarP(&validateExcludeFileName, "excludeFile", "x", "", "Name of the file containing the github handles that should not be present in the data.")
+}
+
+// Main function of the VALIDATE command
+func performValidate(fileOrDir string, reportFileName string, exclusionFileName string) error {
+	var excludedUsers []string
+	if exclusionFileName != "" {
+		var err error
+		err, excludedUsers = load_exclusions(exclusionFileName)
+		if err != nil {
+			return fmt.Errorf("invalid excluded user list => %v\n", err)
+		}
+	}
+
+	filesToCheck, err := listFilesToValidate(fileOrDir)
+	if err != nil {
+		return err
+	}
+
+	report := validationReport{Valid: true}
+	for _, fileName := range filesToCheck {
+		if isVerbose {
+			fmt.Fprintf(os.Stderr, "Validating \"%s\"\n", fileName)
+		}
+		fileReport := validateDataFile(fileName, excludedUsers)
+		report.Files = append(report.Files, fileReport)
+		report.Errors += fileReport.Errors
+		report.Warnings += fileReport.Warnings
+	}
+	report.Valid = report.Errors == 0
+
+	jsonReport, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Unexpected error generating the report: %v\n", err)
+	}
+
+	if reportFileName == "" || isStdStream(reportFileName) {
+		fmt.Println(string(jsonReport))
+	} else {
+		if err := os.WriteFile(reportFileName, append(jsonReport, '\n'), 0644); err != nil {
+			return fmt.Errorf("Unable to write report %s: %v\n", reportFileName, err)
+		}
+	}
+
+	if !report.Valid {
+		return fmt.Errorf("validation failed: %d error(s) found in %d file(s)", report.Errors, len(report.Files))
+	}
+	return nil
+}
+
+// Returns the file to validate, or all the CSV files of the directory (sorted by name)
+func listFilesToValidate(fileOrDir string) ([]string, error) {
+	if !isValidDir(fileOrDir) {
+		return []string{fileOrDir}, nil
+	}
+
+	fileList, err := filepath.Glob(filepath.Join(fileOrDir, "*.csv"))
+	if err != nil {
+		return nil, err
+	}
+	if len(fileList) == 0 {
+		return nil, fmt.Errorf("No CSV file found in \"%s\"", fileOrDir)
+	}
+	sort.Strings(fileList)
+	return fileList, nil
+}
+
+// Performs all the checks on a single data file
+func validateDataFile(fileName string, excludedUsers []string) fileValidationReport {
+	fileReport := fileValidationReport{File: fileName}
+
+	addIssue := func(line int, severity string, check string, message string) {
+		fileReport.Issues = append(fileReport.Issues, validationIssue{Line: line, Severity: severity, Check: check, Message: message})
+		if severity == severityError {
+			fileReport.Errors++
+		} else {
+			fileReport.Warnings++
+		}
+	}
+
+	table, err := loadCSVtable(fileName)
+	if err != nil {
+		addIssue(0, severityError, "header", err.Error())
+		return fileReport
+	}
+
+	fileReport.Kind = table.schema.kind
+	fileReport.Version = table.schema.version
+	fileReport.Records = len(table.records)
+
+	if !table.schema.isCurrent() || !validateHeader(trimmedHeader(table.header), table.schema.columns, false) {
+		addIssue(1, severityWarning, "header",
+			fmt.Sprintf("Header does not match the current %s schema (v%d): use \"migrate\" to upgrade the file",
+				table.schema.kind, currentSchema(table.schema.kind).version))
+	}
+
+	userColumn := userColumnOf(table.schema.kind)
+	seenKeys := make(map[string]int)
+	nbrToleratedFieldCount := 0
+
+	for i, record := range table.records {
+		line := table.lines[i]
+
+		if len(record) != len(table.header) {
+			// A record missing some of the schema's columns can't be used
+			if !hasAllSchemaColumns(table, record) {
+				addIssue(line, severityError, "field_count",
+					fmt.Sprintf("Found %d fields, expected %d", len(record), len(table.header)))
+				continue
+			}
+			// Only unknown columns are missing or added: reported once for the whole file
+			nbrToleratedFieldCount++
+		}
+
+		for _, issue := range checkRecord(table, record) {
+			addIssue(line, severityError, issue.Check, issue.Message)
+		}
+
+		// Duplicates are detected on the record's key (commenters can legitimately have identical records)
+		if key := recordKey(table, record); key != "" {
+			if firstLine, isDuplicate := seenKeys[key]; isDuplicate {
+				addIssue(line, severityError, "duplicate",
+					fmt.Sprintf("\"%s\" is already present at line %d", key, firstLine))
+			} else {
+				seenKeys[key] = line
+			}
+		}
+
+		if userColumn != "" && len(excludedUsers) > 0 {
+			user := table.get(record, userColumn)
+			if isExcludedAuthor(excludedUsers, user) {
+				addIssue(line, severityError, "excluded_user",
+					fmt.Sprintf("User \"%s\" is in the exclusion list", user))
+			}
+		}
+	}
+
+	if nbrToleratedFieldCount > 0 {
+		addIssue(0, severityWarning, "field_count",
+			fmt.Sprintf("%d record(s) do not have the same number of fields as the header (only unknown columns are affected)", nbrToleratedFieldCount))
+	}
+
+	return fileReport
+}
+
+// Returns true if the record contains a field for every column of the schema
+func hasAllSchemaColumns(table csvTable, record []string) bool {
+	for _, column := range table.schema.columns {
+		if table.columnIndex[column] >= len(record) {
+			return false
+		}
+	}
+	return true
+}
+
+// Checks the content of the fields of a record, depending on the kind of data file
+func checkRecord(table csvTable, record []string) []validationIssue {
+	var issues []validationIssue
+
+	switch table.schema.kind {
+	case schemaSubmitters:
+		if err := validatePRfields(table.get(record, "org"), table.get(record, "repository"), table.get(record, "number")); err != nil {
+			issues = append(issues, validationIssue{Check: "pr_spec", Message: err.Error()})
+		}
+		createdAt, err := time.Parse(time.RFC3339, table.get(record, "created_at"))
+		if err != nil {
+			issues = append(issues, validationIssue{Check: "created_at",
+				Message: fmt.Sprintf("Invalid creation date \"%s\"", table.get(record, "created_at"))})
+		} else if createdAt.UTC().Format("2006-01") != table.get(record, "month_year") {
+			issues = append(issues, validationIssue{Check: "month",
+				Message: fmt.Sprintf("month_year \"%s\" does not match created_at \"%s\"",
+					table.get(record, "month_year"), table.get(record, "created_at"))})
+		}
+		// The other dates are optional
+		for _, column := range []string{"closed_at", "first_response_at", "first_approval_at"} {
+			if value := table.get(record, column); value != "" {
+				if _, err := time.Parse(time.RFC3339, value); err != nil {
+					issues = append(issues, validationIssue{Check: column,
+						Message: fmt.Sprintf("Invalid %s date \"%s\"", column, value)})
+				}
+			}
+		}
+
+	case schemaCommenters:
+		if _, _, _, err := validatePRspec(table.get(record, "PR_ref")); err != nil {
+			issues = append(issues, validationIssue{Check: "pr_spec", Message: strings.TrimSpace(err.Error())})
+		}
+		if !isValidMonthFormat(table.get(record, "month")) {
+			issues = append(issues, validationIssue{Check: "month",
+				Message: fmt.Sprintf("Invalid month \"%s\"", table.get(record, "month"))})
+		}
+
+	case schemaCommentsCount:
+		if !isValidMonthFormat(table.get(record, "month")) {
+			issues = append(issues, validationIssue{Check: "month",
+				Message: fmt.Sprintf("Invalid month \"%s\"", table.get(record, "month"))})
+		}
+		for _, column := range []string{"comments", "PRs"} {
+			if !prNbr_regexp.MatchString(table.get(record, column)) {
+				issues = append(issues, validationIssue{Check: "count",
+					Message: fmt.Sprintf("%s count \"%s\" is not a (positive) number", column, table.get(record, column))})
+			}
+		}
+
+	case schemaPrPerSubmitter, schemaPrPerRepo:
+		if !isValidOrgFormat(table.get(record, "user")) {
+			issues = append(issues, validationIssue{Check: "user",
+				Message: fmt.Sprintf("Invalid GitHub user \"%s\"", table.get(record, "user"))})
+		}
+		if !prNbr_regexp.MatchString(table.get(record, "PR")) {
+			issues = append(issues, validationIssue{Check: "pr_count",
+				Message: fmt.Sprintf("PR count \"%s\" is not a (positive) number", table.get(record, "PR"))})
+		}
+	}
+
+	return issues
+}
+
+// Returns the value identifying a record, used to detect duplicates (empty if not applicable)
+func recordKey(table csvTable, record []string) string {
+	switch table.schema.kind {
+	case schemaSubmitters:
+		return fmt.Sprintf("%s/%s/%s", table.get(record, "org"), table.get(record, "repository"), table.get(record, "number"))
+	case schemaPrPerSubmitter:
+		return strings.ToLower(table.get(record, "user"))
+	case schemaPrPerRepo:
+		return strings.ToLower(table.get(record, "user")) + " " + table.get(record, "repository")
+	case schemaCommentsCount:
+		return strings.ToLower(table.get(record, "commenter")) + " " + table.get(record, "month")
+	case schemaUserProfiles:
+		return strings.ToLower(table.get(record, "login"))
+	}
+	return ""
+}
+
+// Returns the name of the column containing the GitHub user, depending on the kind of data file
+func userColumnOf(kind string) string {
+	switch kind {
+	case schemaSubmitters:
+		return "user.login"
+	case schemaCommenters, schemaCommentsCount:
+		return "commenter"
+	case schemaPrPerSubmitter, schemaPrPerRepo:
+		return "user"
+	case schemaHonor:
+		return "GH_HANDLE"
+	case schemaUserProfiles:
+		return "login"
+	}
+	return ""
+}
@@ -0,0 +1,152 @@
+/*
+Copyright © 2024 Jean-Marc Meessen jean-marc@meessen-web.org
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_validateDataFile(t *testing.T) {
+	type issueRef struct {
+		line  int
+		check string
+	}
+	tests := []struct {
+		name          string
+		fileName      string
+		excludedUsers []string
+		wantKind      string
+		wantIssues    []issueRef
+	}{
+		{
+			"valid submissions file",
+			"../test-data/monthly/submissions-2024-01.csv",
+			nil,
+			schemaSubmitters,
+			nil,
+		},
+		{
+			"submissions file needing migration",
+			"../test-data/small-submission-list.csv",
+			nil,
+			schemaSubmitters,
+			[]issueRef{{1, "header"}},
+		},
+		{
+			"pr_per_submitter file needing migration",
+			"../test-data/pr_per_submitter-2024-03.csv",
+			nil,
+			schemaPrPerSubmitter,
+			[]issueRef{{1, "header"}, {0, "field_count"}},
+		},
+		{
+			"unrecognized file",
+			"../test-data/pr_per_submitter-2024-02.csv",
+			nil,
+			"",
+			[]issueRef{{0, "header"}},
+		},
+		{
+			"invalid submissions file",
+			"../test-data/invalid-submission-list.csv",
+			[]string{"markewaite", "basil"},
+			schemaSubmitters,
+			[]issueRef{
+				{1, "header"},
+				{2, "excluded_user"},
+				{5, "duplicate"},
+				{6, "month"},
+				{7, "pr_spec"},
+				{8, "field_count"},
+				{9, "excluded_user"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validateDataFile(tt.fileName, tt.excludedUsers)
+			assert.Equal(t, tt.wantKind, got.Kind)
+
+			var gotIssues []issueRef
+			for _, issue := range got.Issues {
+				gotIssues = append(gotIssues, issueRef{issue.Line, issue.Check})
+			}
+			if !reflect.DeepEqual(gotIssues, tt.wantIssues) {
+				t.Errorf("validateDataFile() issues = %v, want %v", gotIssues, tt.wantIssues)
+			}
+		})
+	}
+}
+
+func Test_performValidate(t *testing.T) {
+	tempDir := t.TempDir()
+	reportFileName := filepath.Join(tempDir, "report.json")
+
+	err := performValidate("../test-data/invalid-submission-list.csv", reportFileName, "../test-data/test-exclusion.txt")
+	assert.Error(t, err, "Validation of an invalid file should fail")
+
+	content, err := os.ReadFile(reportFileName)
+	assert.NoError(t, err, "Report should have been written")
+	var report validationReport
+	assert.NoError(t, json.Unmarshal(content, &report), "Report should be valid JSON")
+	assert.False(t, report.Valid)
+	assert.Equal(t, 6, report.Errors)
+	assert.Equal(t, 1, len(report.Files))
+}
+
+func Test_performValidate_directory(t *testing.T) {
+	tempDir := t.TempDir()
+	_, err := duplicateFile("../test-data/submissions-2023-08.csv", tempDir, false)
+	assert.NoError(t, err, "Unexpected data file duplication error")
+	_, err = duplicateFile("../test-data/pr_per_submitter-2024-03.csv", tempDir, false)
+	assert.NoError(t, err, "Unexpected data file duplication error")
+	reportFileName := filepath.Join(t.TempDir(), "report.json")
+
+	err = performValidate(tempDir, reportFileName, "")
+
+	assert.NoError(t, err, "Directory should be valid (warnings only)")
+	content, _ := os.ReadFile(reportFileName)
+	var report validationReport
+	assert.NoError(t, json.Unmarshal(content, &report), "Report should be valid JSON")
+	assert.True(t, report.Valid)
+	assert.Equal(t, 2, len(report.Files))
+	assert.Equal(t, 3, report.Warnings)
+}
+
+func Test_checkRecord_lifecycleDates(t *testing.T) {
+	input := "org,repository,number,url,state,created_at,merged_at,user.login,month_year,title,closed_at,first_response_at,first_approval_at\n" +
+		"\"jenkinsci\",\"jenkins\",1,\"https://github.com/jenkinsci/jenkins/pull/1\",\"OPEN\",\"2024-01-03T10:00:00Z\",\"\",\"alice\",\"2024-01\",\"Fix\",\"\",\"yesterday\",\"2024-01-04T10:00:00Z\"\n"
+	table, err := readCSVtable(strings.NewReader(input))
+	assert.NoError(t, err, "Unexpected error reading table")
+
+	issues := checkRecord(table, table.records[0])
+
+	assert.Equal(t, 1, len(issues))
+	assert.Equal(t, "first_response_at", issues[0].Check)
+}